@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/validator"
+)
+
+func (app *Application) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	input.Username = strings.TrimSpace(input.Username)
+	checkUsername(v, input.Username)
+	checkPassword(v, input.Password)
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	account, err := core.NewAccount(input.Username, input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.CreateAccount(ctx, &account)
+	if err != nil {
+		if errors.Is(err, core.ErrExist) {
+			app.conflictResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: created account %d %q requested by %s\n", account.ID, account.Username, r.RemoteAddr)
+
+	w.Header().Set("Location", "/api/me")
+	err = writeJSON(w, 201, envelope{"account": account})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Username != "", "username", "must not be empty")
+	v.Check(input.Password != "", "password", "must not be empty")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	account, err := app.storage.ReadAccountByUsername(ctx, strings.TrimSpace(input.Username))
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.invalidCredentialsResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	ok, err := account.PasswordMatches(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !ok {
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	session, token, err := core.NewSession(account.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.CreateSession(ctx, &session)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	resp := envelope{
+		"token":  token,
+		"expiry": session.Expiry,
+	}
+	err = writeJSON(w, 201, envelope{"session": resp})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	// requireAccount has already checked the token
+	token, _ := bearerToken(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err := app.storage.DeleteSession(ctx, core.HashSessionToken(token))
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"message": "session successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func checkUsername(v *validator.Validator, username string) {
+	v.Check(len(username) >= core.MinUsernameLength, "username", fmt.Sprintf("must be at least %d bytes long", core.MinUsernameLength))
+	v.Check(len(username) <= core.MaxUsernameLength, "username", fmt.Sprintf("must not be more than %d bytes long", core.MaxUsernameLength))
+	v.Check(core.IsUsername(username), "username", "must only contain letters, digits, dashes and underscores")
+}
+
+func checkPassword(v *validator.Validator, password string) {
+	v.Check(len(password) >= core.MinPasswordLength, "password", fmt.Sprintf("must be at least %d bytes long", core.MinPasswordLength))
+	v.Check(len(password) <= core.MaxPasswordLength, "password", fmt.Sprintf("must not be more than %d bytes long", core.MaxPasswordLength))
+}
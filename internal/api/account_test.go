@@ -0,0 +1,229 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestHandleCreateAccount(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	username := test.RandomString(32)
+
+	input := fmt.Sprintf(`{"username": %q, "password": %q}`, username, test.RandomString(32))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/account", strings.NewReader(input))
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env map[string]core.Account
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["account"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "account")
+	}
+
+	if got.Username != username {
+		t.Fatalf("want %v, got %v", username, got.Username)
+	}
+
+	// the password hash should never be returned
+	if strings.Contains(string(body), "password") {
+		t.Fatalf("response should not include the password: %s", body)
+	}
+
+	// the same username (in any case) should conflict
+	input = fmt.Sprintf(`{"username": %q, "password": %q}`, strings.ToUpper(username), test.RandomString(32))
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/account", strings.NewReader(input))
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateAccountInvalid(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	tests := []string{
+		`{"username": "al", "password": "correct horse"}`,
+		`{"username": "alice smith", "password": "correct horse"}`,
+		`{"username": "alice", "password": "short"}`,
+		`{"username": "alice"}`,
+	}
+
+	router := app.Router()
+	for _, input := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/account", strings.NewReader(input))
+
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 400 {
+			t.Fatalf("%s: want %v, got %v", input, 400, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleCreateSession(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, password := test.CreateMockAccount(storage, t)
+
+	input := fmt.Sprintf(`{"username": %q, "password": %q}`, account.Username, password)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/session", strings.NewReader(input))
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env map[string]struct {
+		Token string `json:"token"`
+	}
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, ok := env["session"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "session")
+	}
+
+	// the token should identify the account
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/me", nil)
+	r.Header.Set("Authorization", "Bearer "+session.Token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var me map[string]core.Account
+	err = json.Unmarshal(body, &me)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if me["account"].ID != account.ID {
+		t.Fatalf("want %v, got %v", account.ID, me["account"].ID)
+	}
+
+	// logging out should end the session
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", "/session", nil)
+	r.Header.Set("Authorization", "Bearer "+session.Token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/me", nil)
+	r.Header.Set("Authorization", "Bearer "+session.Token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 401 {
+		t.Fatalf("want %v, got %v", 401, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateSessionInvalidCredentials(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+
+	tests := []string{
+		fmt.Sprintf(`{"username": %q, "password": %q}`, account.Username, test.RandomString(32)),
+		fmt.Sprintf(`{"username": %q, "password": %q}`, test.RandomString(32), test.RandomString(32)),
+	}
+
+	router := app.Router()
+	for _, input := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/session", strings.NewReader(input))
+
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 401 {
+			t.Fatalf("%s: want %v, got %v", input, 401, resp.StatusCode)
+		}
+	}
+}
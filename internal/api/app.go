@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/cors"
 
 	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
 )
 
 var (
@@ -22,25 +23,34 @@ var (
 var templatesFS embed.FS
 
 type Application struct {
-	templates fs.FS
-	storage   core.Storage
-	logger    *log.Logger
+	templates  fs.FS
+	storage    core.Storage
+	reader     feed.Reader
+	adminToken string
+	logger     *log.Logger
 }
 
-func NewApplication(storage core.Storage, logger *log.Logger) *Application {
+// NewApplication creates the API application. Admin endpoints require the
+// given bearer token and are disabled entirely if it is empty.
+func NewApplication(storage core.Storage, reader feed.Reader, adminToken string, logger *log.Logger) *Application {
 	templates, _ := fs.Sub(templatesFS, "templates")
 
 	app := Application{
-		templates: templates,
-		storage:   storage,
-		logger:    logger,
+		templates:  templates,
+		storage:    storage,
+		reader:     reader,
+		adminToken: adminToken,
+		logger:     logger,
 	}
 	return &app
 }
 
 func (app *Application) Router() http.Handler {
 	r := chi.NewRouter()
-	r.Use(cors.Handler(cors.Options{}))
+	r.Use(cors.Handler(cors.Options{
+		AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	}))
 	r.Use(middleware.Recoverer)
 
 	r.NotFound(app.notFoundResponse)
@@ -48,9 +58,49 @@ func (app *Application) Router() http.Handler {
 
 	r.Get("/", app.HandleIndex)
 	r.Get("/blog", app.HandleReadBlogs)
+	r.Get("/blog.opml", app.HandleExportBlogs)
 	r.Get("/blog/{id}", app.HandleReadBlog)
+	r.Get("/blog/{id}/status", app.HandleReadBlogStatus)
 	r.Get("/post", app.HandleReadPosts)
 	r.Get("/post/{id}", app.HandleReadPost)
+	r.Get("/tag", app.HandleReadTags)
+	r.Get("/tag/{id}", app.HandleReadTag)
+
+	r.Post("/account", app.HandleCreateAccount)
+	r.Post("/session", app.HandleCreateSession)
+
+	// account endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireAccount)
+
+		r.Delete("/session", app.HandleDeleteSession)
+		r.Get("/me", app.HandleReadMe)
+		r.Get("/me/blog", app.HandleReadSubscriptions)
+		r.Post("/me/blog", app.HandleCreateSubscription)
+		r.Delete("/me/blog/{id}", app.HandleDeleteSubscription)
+		r.Get("/me/post", app.HandleReadSubscribedPosts)
+		r.Get("/me/saved", app.HandleReadSavedPosts)
+		r.Post("/me/saved", app.HandleSavePost)
+		r.Delete("/me/saved/{id}", app.HandleUnsavePost)
+	})
+
+	// admin endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireAdmin)
+
+		r.Post("/blog", app.HandleCreateBlog)
+		r.Patch("/blog/{id}", app.HandleUpdateBlog)
+		r.Delete("/blog/{id}", app.HandleDeleteBlog)
+		r.Post("/tag", app.HandleCreateTag)
+		r.Delete("/tag/{id}", app.HandleDeleteTag)
+		r.Post("/tag/{id}/alias", app.HandleCreateTagAlias)
+		r.Delete("/tag/{id}/alias/{alias}", app.HandleDeleteTagAlias)
+		r.Get("/webhook", app.HandleReadWebhooks)
+		r.Post("/webhook", app.HandleCreateWebhook)
+		r.Get("/webhook/{id}", app.HandleReadWebhook)
+		r.Delete("/webhook/{id}", app.HandleDeleteWebhook)
+		r.Get("/webhook/{id}/delivery", app.HandleReadWebhookDeliveries)
+	})
 
 	return r
 }
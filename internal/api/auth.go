@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (app *Application) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// admin endpoints are disabled without a configured token
+		if app.adminToken == "" {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(app.adminToken)) != 1 {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAccount only allows requests with the bearer token of an
+// unexpired session and makes its account available to handlers.
+func (app *Application) requireAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+
+		account, err := app.storage.ReadAccountBySession(ctx, core.HashSessionToken(token))
+		if err != nil {
+			if errors.Is(err, core.ErrNotExist) {
+				app.unauthorizedResponse(w, r)
+				return
+			}
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		r = contextSetAccount(r, account)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", false
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return "", false
+	}
+
+	return token, true
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/theandrew168/bloggulus/internal/canonical"
 	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/opml"
 	"github.com/theandrew168/bloggulus/internal/validator"
 )
 
@@ -59,6 +64,9 @@ func (app *Application) HandleReadBlogs(w http.ResponseWriter, r *http.Request)
 	offset := readInt(qs, "offset", 0, v)
 	v.Check(offset >= 0, "offset", "must be positive")
 
+	status := qs.Get("status")
+	v.Check(status == "" || status == "failing", "status", "must be 'failing' if provided")
+
 	if !v.Valid() {
 		app.badRequestResponse(w, r, v.Errors)
 		return
@@ -67,7 +75,14 @@ func (app *Application) HandleReadBlogs(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
-	blogs, err := app.storage.ReadBlogs(ctx, limit, offset)
+	var blogs []core.Blog
+	var err error
+	if status == "failing" {
+		// only blogs whose most recent sync failed
+		blogs, err = app.storage.ReadFailingBlogs(ctx, limit, offset)
+	} else {
+		blogs, err = app.storage.ReadBlogs(ctx, limit, offset)
+	}
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -79,3 +94,293 @@ func (app *Application) HandleReadBlogs(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+func (app *Application) HandleExportBlogs(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	offset := 0
+
+	// read every blog in batches
+	var blogs []core.Blog
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		batch, err := app.storage.ReadBlogs(ctx, limit, offset)
+		cancel()
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		blogs = append(blogs, batch...)
+		offset += limit
+	}
+
+	// render to a temp buffer so errors can still be reported
+	var buf bytes.Buffer
+	doc := opml.New("Bloggulus", blogs)
+	err := opml.Write(&buf, doc)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="bloggulus.opml"`)
+	w.Write(buf.Bytes())
+}
+
+func (app *Application) HandleReadBlogStatus(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// ensure the blog exists before checking its status
+	_, err = app.storage.ReadBlog(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	status, err := app.storage.ReadSyncStatus(ctx, id)
+	if err != nil {
+		// blogs that haven't been synced yet have an empty status
+		if !errors.Is(err, core.ErrNotExist) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		status = core.SyncStatus{BlogID: id}
+	}
+
+	err = writeJSON(w, 200, envelope{"status": status})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateBlog(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		FeedURL string `json:"feed_url"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.FeedURL != "", "feed_url", "must be provided")
+	v.Check(validator.IsURL(input.FeedURL), "feed_url", "must be a valid URL")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	// ensure the feed can actually be read
+	blog, err := app.reader.ReadBlog(input.FeedURL)
+	if err != nil {
+		app.logger.Println(err)
+		v.AddError("feed_url", "must be a readable feed or a page that links to one")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.CreateBlog(ctx, &blog)
+	if err != nil {
+		if errors.Is(err, core.ErrExist) {
+			app.conflictResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/blog/%d", blog.ID))
+	err = writeJSON(w, 201, envelope{"blog": blog})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleUpdateBlog(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	// pointers differentiate between missing and empty fields
+	var input struct {
+		FeedURL *string `json:"feed_url"`
+		SiteURL *string `json:"site_url"`
+		Title   *string `json:"title"`
+	}
+
+	err = readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	if input.FeedURL != nil {
+		v.Check(validator.IsURL(*input.FeedURL), "feed_url", "must be a valid URL")
+	}
+	if input.SiteURL != nil {
+		v.Check(validator.IsURL(*input.SiteURL), "site_url", "must be a valid URL")
+	}
+	if input.Title != nil {
+		v.Check(*input.Title != "", "title", "must not be empty")
+	}
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	blog, err := app.storage.ReadBlog(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// links that differ only in form (http vs https, etc) are the same feed
+	if input.FeedURL != nil && canonical.URL(*input.FeedURL) != canonical.URL(blog.FeedURL) {
+		// ensure the new feed can actually be read (a page is resolved to
+		// the feed that it links to)
+		found, err := app.reader.ReadBlog(*input.FeedURL)
+		if err != nil {
+			app.logger.Println(err)
+			v.AddError("feed_url", "must be a readable feed or a page that links to one")
+			app.badRequestResponse(w, r, v.Errors)
+			return
+		}
+
+		// forget about the old feed and sync the new one right away
+		blog.FeedURL = found.FeedURL
+		blog.SiteURL = found.SiteURL
+		blog.ETag = ""
+		blog.LastModified = ""
+		blog.TTL = 0
+		blog.NextSyncAt = time.Time{}
+	} else if input.FeedURL != nil {
+		// still the same feed (but maybe fetched over http instead)
+		blog.FeedURL = *input.FeedURL
+	}
+	if input.SiteURL != nil {
+		blog.SiteURL = *input.SiteURL
+	}
+	if input.Title != nil {
+		blog.Title = *input.Title
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.UpdateBlog(ctx, blog)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrExist):
+			app.conflictResponse(w, r)
+		case errors.Is(err, core.ErrNotExist):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"blog": blog})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteBlog(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// read the blog first so the audit log can describe it
+	blog, err := app.storage.ReadBlog(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.DeleteBlog(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: deleted blog %d %q (%s) requested by %s\n", blog.ID, blog.Title, blog.FeedURL, r.RemoteAddr)
+
+	err = writeJSON(w, 200, envelope{"message": "blog successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -1,14 +1,19 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/theandrew168/bloggulus/internal/api"
 	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/opml"
 	"github.com/theandrew168/bloggulus/internal/postgresql"
 	"github.com/theandrew168/bloggulus/internal/test"
 )
@@ -18,8 +23,9 @@ func TestHandleReadBlog(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	blog := test.CreateMockBlog(storage, t)
 
@@ -61,8 +67,9 @@ func TestHandleReadBlogNotFound(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/blog/999999999", nil)
@@ -81,8 +88,9 @@ func TestHandleReadBlogs(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	test.CreateMockBlog(storage, t)
 
@@ -123,8 +131,9 @@ func TestHandleReadBlogsPagination(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	// create 5 blogs to test with
 	test.CreateMockBlog(storage, t)
@@ -177,3 +186,384 @@ func TestHandleReadBlogsPagination(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleReadBlogStatus(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	blog := test.CreateMockFailingBlog(storage, t)
+
+	url := fmt.Sprintf("/blog/%d/status", blog.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", url, nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string]core.SyncStatus
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["status"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "status")
+	}
+
+	if got.BlogID != blog.ID {
+		t.Fatalf("want %v, got %v", blog.ID, got.BlogID)
+	}
+	if got.FailureCount != 1 {
+		t.Fatalf("want %v, got %v", 1, got.FailureCount)
+	}
+}
+
+func TestHandleReadBlogStatusNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/blog/999999999/status", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleReadBlogsFailing(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	test.CreateMockFailingBlog(storage, t)
+	healthy := test.CreateMockBlog(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/blog?status=failing&limit=50", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Blog
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["blogs"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "blogs")
+	}
+
+	if len(got) < 1 {
+		t.Fatalf("expected at least one failing blog")
+	}
+
+	for _, blog := range got {
+		if blog.ID == healthy.ID {
+			t.Fatalf("expected blog %v to not be failing", healthy.ID)
+		}
+	}
+}
+
+func TestHandleCreateBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	blog := test.NewMockBlog()
+	reader := feed.NewMockReader(blog, nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	input := fmt.Sprintf(`{"feed_url": %q}`, blog.FeedURL)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/blog", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env map[string]core.Blog
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["blog"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "blog")
+	}
+
+	if got.FeedURL != blog.FeedURL {
+		t.Fatalf("want %v, got %v", blog.FeedURL, got.FeedURL)
+	}
+
+	// creating the same blog again should conflict
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/blog", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateBlogUnauthorized(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	blog := test.NewMockBlog()
+	reader := feed.NewMockReader(blog, nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+
+	tests := []struct {
+		adminToken string
+		header     string
+	}{
+		{token, ""},
+		{token, "Bearer " + test.RandomString(32)},
+		{token, "Basic " + token},
+		// admin API is disabled without a token
+		{"", "Bearer "},
+	}
+
+	for _, test := range tests {
+		app := api.NewApplication(storage, reader, test.adminToken, logger)
+
+		input := fmt.Sprintf(`{"feed_url": %q}`, blog.FeedURL)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/blog", strings.NewReader(input))
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 401 {
+			t.Fatalf("want %v, got %v", 401, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleUpdateBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+	title := test.RandomString(32)
+
+	url := fmt.Sprintf("/blog/%d", blog.ID)
+	input := fmt.Sprintf(`{"title": %q}`, title)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", url, strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != title {
+		t.Fatalf("want %v, got %v", title, got.Title)
+	}
+	if got.FeedURL != blog.FeedURL {
+		t.Fatalf("want %v, got %v", blog.FeedURL, got.FeedURL)
+	}
+}
+
+func TestHandleUpdateBlogFeedURL(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// the reader resolves any page to this blog's feed
+	found := test.NewMockBlog()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(found, nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+
+	// point the blog at a homepage rather than its feed
+	url := fmt.Sprintf("/blog/%d", blog.ID)
+	input := fmt.Sprintf(`{"feed_url": %q}`, test.RandomURL(32))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", url, strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FeedURL != found.FeedURL {
+		t.Fatalf("want %v, got %v", found.FeedURL, got.FeedURL)
+	}
+	if got.SiteURL != found.SiteURL {
+		t.Fatalf("want %v, got %v", found.SiteURL, got.SiteURL)
+	}
+}
+
+func TestHandleDeleteBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	post := test.CreateMockPost(storage, t)
+
+	url := fmt.Sprintf("/blog/%d", post.Blog.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	_, err := storage.ReadBlog(context.Background(), post.Blog.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted blog should not exist")
+	}
+
+	// deleting it again should not find anything
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleExportBlogs(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	blog := test.CreateMockBlog(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/blog.opml", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	doc, err := opml.Parse(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, feed := range doc.Feeds() {
+		if feed.URL == blog.FeedURL {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected blog %v in export", blog.FeedURL)
+	}
+}
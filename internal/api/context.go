@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+type contextKey string
+
+const accountContextKey = contextKey("account")
+
+func contextSetAccount(r *http.Request, account core.Account) *http.Request {
+	ctx := context.WithValue(r.Context(), accountContextKey, account)
+	return r.WithContext(ctx)
+}
+
+// contextGetAccount returns the account set by requireAccount (so it must
+// only be called by handlers behind that middleware).
+func contextGetAccount(r *http.Request) core.Account {
+	account, ok := r.Context().Value(accountContextKey).(core.Account)
+	if !ok {
+		panic("missing account value in request context")
+	}
+
+	return account
+}
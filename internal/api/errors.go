@@ -19,6 +19,22 @@ func (app *Application) badRequestResponse(w http.ResponseWriter, r *http.Reques
 	app.errorResponse(w, r, 400, errors)
 }
 
+func (app *Application) badRequestMessageResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.errorResponse(w, r, 400, err.Error())
+}
+
+func (app *Application) unauthorizedResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid or missing authentication token"
+	app.errorResponse(w, r, 401, message)
+}
+
+func (app *Application) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid username or password"
+	app.errorResponse(w, r, 401, message)
+}
+
 func (app *Application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "not found"
 	app.errorResponse(w, r, 404, message)
@@ -29,6 +45,11 @@ func (app *Application) methodNotAllowedResponse(w http.ResponseWriter, r *http.
 	app.errorResponse(w, r, 405, message)
 }
 
+func (app *Application) conflictResponse(w http.ResponseWriter, r *http.Request) {
+	message := "already exists"
+	app.errorResponse(w, r, 409, message)
+}
+
 func (app *Application) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	// skip 2 frames to identify original caller
 	app.logger.Output(2, err.Error())
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/feed"
 	"github.com/theandrew168/bloggulus/internal/postgresql"
 	"github.com/theandrew168/bloggulus/internal/test"
 )
@@ -16,8 +17,9 @@ func TestBadRequest(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	tests := []struct {
 		url  string
@@ -30,6 +32,9 @@ func TestBadRequest(t *testing.T) {
 		{"/blog?limit=123", "less than"},
 		{"/blog?offset=asdf", "integer"},
 		{"/blog?offset=-123", "positive"},
+		{"/blog?status=asdf", "failing"},
+		{"/blog/invalid/status", "integer"},
+		{"/blog/-123/status", "positive"},
 		{"/post/invalid", "integer"},
 		{"/post/-123", "positive"},
 		{"/post?limit=asdf", "integer"},
@@ -37,6 +42,8 @@ func TestBadRequest(t *testing.T) {
 		{"/post?limit=123", "less than"},
 		{"/post?offset=asdf", "integer"},
 		{"/post?offset=-123", "positive"},
+		{"/post?cursor=asdf", "valid cursor"},
+		{"/post?cursor=eyJpIjo3fQ&offset=0", "combined"},
 	}
 
 	for _, test := range tests {
@@ -68,8 +75,9 @@ func TestNotFound(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/missing", nil)
@@ -98,8 +106,9 @@ func TestMethodNotAllowed(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("PUT", "/", nil)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/feed"
 	"github.com/theandrew168/bloggulus/internal/postgresql"
 	"github.com/theandrew168/bloggulus/internal/test"
 )
@@ -15,8 +16,9 @@ func TestHandleIndex(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
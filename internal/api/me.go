@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/validator"
+)
+
+func (app *Application) HandleReadMe(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	err := writeJSON(w, 200, envelope{"account": account})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadSubscriptions(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 200, "limit", "must be less than or equal to 200")
+
+	offset := readInt(qs, "offset", 0, v)
+	v.Check(offset >= 0, "offset", "must be positive")
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	blogs, err := app.storage.ReadSubscriptions(ctx, account.ID, limit, offset)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"blogs": blogs})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	var input struct {
+		BlogID int `json:"blog_id"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.BlogID > 0, "blog_id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.CreateSubscription(ctx, account.ID, input.BlogID)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrExist):
+			app.conflictResponse(w, r)
+		case errors.Is(err, core.ErrNotExist):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	blog, err := app.storage.ReadBlog(ctx, input.BlogID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 201, envelope{"blog": blog})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.DeleteSubscription(ctx, account.ID, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"message": "subscription successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadSubscribedPosts(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 50, "limit", "must be less than or equal to 50")
+
+	cursor := readCursor(qs, "cursor", v)
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	posts, next, err := app.storage.ReadSubscribedPostsAfter(ctx, account.ID, cursor, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.ReadPostMarks(ctx, account.ID, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"posts": posts, "next": nextCursor(next)})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadSavedPosts(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 50, "limit", "must be less than or equal to 50")
+
+	cursor := readCursor(qs, "cursor", v)
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	posts, next, err := app.storage.ReadSavedPostsAfter(ctx, account.ID, cursor, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.ReadPostMarks(ctx, account.ID, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"posts": posts, "next": nextCursor(next)})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleSavePost(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	var input struct {
+		PostID int `json:"post_id"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.PostID > 0, "post_id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.SavePost(ctx, account.ID, input.PostID)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrExist):
+			app.conflictResponse(w, r)
+		case errors.Is(err, core.ErrNotExist):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	post, err := app.storage.ReadPost(ctx, input.PostID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	posts := []core.Post{post}
+	err = app.storage.ReadPostMarks(ctx, account.ID, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 201, envelope{"post": posts[0]})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleUnsavePost(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.UnsavePost(ctx, account.ID, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"message": "saved post successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,407 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestHandleReadMeUnauthorized(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	tests := []string{
+		"",
+		"Bearer " + test.RandomString(32),
+		// the admin token doesn't belong to an account
+		"Bearer " + token,
+	}
+
+	router := app.Router()
+	for _, header := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/me/post", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 401 {
+			t.Fatalf("%q: want %v, got %v", header, 401, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleSubscriptions(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	blog := test.CreateMockBlog(storage, t)
+
+	// subscribe to the blog
+	input := fmt.Sprintf(`{"blog_id": %d}`, blog.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/me/blog", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	// subscribing twice should conflict
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/me/blog", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+
+	// the blog should be listed
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/me/blog", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Blog
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blogs := env["blogs"]
+	if len(blogs) != 1 || blogs[0].ID != blog.ID {
+		t.Fatalf("want %v, got %v", []core.Blog{blog}, blogs)
+	}
+
+	// unsubscribe
+	url := fmt.Sprintf("/me/blog/%d", blog.ID)
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	// unsubscribing twice should not be found
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateSubscriptionNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/me/blog", strings.NewReader(`{"blog_id": 999999999}`))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleReadSubscribedPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+
+	// one post from a subscribed blog and one from elsewhere
+	post := test.CreateMockPost(storage, t)
+	other := test.CreateMockPost(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, post.Blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/me/post", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Post
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := env["posts"]
+	if len(posts) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(posts))
+	}
+	if posts[0].ID != post.ID {
+		t.Fatalf("want %v, got %v", post.ID, posts[0].ID)
+	}
+	if posts[0].ID == other.ID {
+		t.Fatalf("post %v is not from a subscribed blog", other.ID)
+	}
+}
+
+func TestHandleSavedPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	post := test.CreateMockPost(storage, t)
+
+	// save the post
+	input := fmt.Sprintf(`{"post_id": %d}`, post.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/me/saved", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	// saving twice should conflict
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/me/saved", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+
+	// the post should be listed
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/me/saved", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Post
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := env["posts"]
+	if len(posts) != 1 || posts[0].ID != post.ID {
+		t.Fatalf("want %v, got %v", []core.Post{post}, posts)
+	}
+	if !posts[0].Saved {
+		t.Fatalf("post %v should be marked saved", post.ID)
+	}
+
+	// unsave the post
+	url := fmt.Sprintf("/me/saved/%d", post.ID)
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	// unsaving twice should not be found
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleSubscriptionsUnread(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+
+	// subscribe to a blog with one read and one unread post
+	read := test.CreateMockPost(storage, t)
+	unread := test.NewMockPost(read.Blog)
+	err := storage.CreatePost(context.Background(), &unread)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.CreateSubscription(context.Background(), account.ID, read.Blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.MarkPostRead(context.Background(), account.ID, read.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/me/blog", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Subscription
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blogs := env["blogs"]
+	if len(blogs) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(blogs))
+	}
+	if blogs[0].Unread != 1 {
+		t.Fatalf("want %v, got %v", 1, blogs[0].Unread)
+	}
+
+	// the feed should show which post was read
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/me/post", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feed map[string][]core.Post
+	err = json.Unmarshal(body, &feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, post := range feed["posts"] {
+		if post.Read != (post.ID == read.ID) {
+			t.Fatalf("post %v: want read %v, got %v", post.ID, post.ID == read.ID, post.Read)
+		}
+	}
+}
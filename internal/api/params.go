@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/theandrew168/bloggulus/internal/core"
 	"github.com/theandrew168/bloggulus/internal/validator"
 )
 
@@ -21,3 +22,28 @@ func readInt(qs url.Values, key string, defaultValue int, v *validator.Validator
 
 	return i
 }
+
+// readInts reads every value of a repeated integer parameter.
+func readInts(qs url.Values, key string, v *validator.Validator) []int {
+	var ints []int
+	for _, s := range qs[key] {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			v.AddError(key, "must be an integer")
+			continue
+		}
+		ints = append(ints, i)
+	}
+
+	return ints
+}
+
+func readCursor(qs url.Values, key string, v *validator.Validator) core.Cursor {
+	cursor, err := core.ParseCursor(qs.Get(key))
+	if err != nil {
+		v.AddError(key, "must be a valid cursor")
+		return core.Cursor{}
+	}
+
+	return cursor
+}
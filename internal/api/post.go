@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -59,41 +60,101 @@ func (app *Application) HandleReadPosts(w http.ResponseWriter, r *http.Request)
 	offset := readInt(qs, "offset", 0, v)
 	v.Check(offset >= 0, "offset", "must be positive")
 
-	q := qs.Get("q")
+	cursor := readCursor(qs, "cursor", v)
+	v.Check(cursor.IsZero() || qs.Get("offset") == "", "cursor", "must not be combined with offset")
+
+	// the search expression may carry filters of its own (like tag:Go)
+	now := time.Now()
+	search := core.ParseSearch(qs.Get("q"), now)
+
+	search.BlogIDs = append(search.BlogIDs, readInts(qs, "blog", v)...)
+	for _, id := range search.BlogIDs {
+		v.Check(id > 0, "blog", "must be positive")
+	}
+
+	search.Tags = append(search.Tags, qs["tag"]...)
+
+	if qs.Get("since") != "" {
+		since, ok := core.ParseSearchSince(qs.Get("since"), now)
+		v.Check(ok, "since", "must be a date, timestamp or relative time (like 6m)")
+		search.Since = since
+	}
+	if qs.Get("until") != "" {
+		until, ok := core.ParseSearchUntil(qs.Get("until"), now)
+		v.Check(ok, "until", "must be a date, timestamp or relative time (like 6m)")
+		search.Until = until
+	}
+
+	if qs.Get("sort") != "" {
+		search.Sort = qs.Get("sort")
+		v.Check(core.IsSearchSort(search.Sort), "sort", "must be relevance or date")
+	}
+
+	v.Check(!search.HasFilters() || qs.Get("offset") == "", "offset", "must not be combined with search filters")
 
 	if !v.Valid() {
 		app.badRequestResponse(w, r, v.Errors)
 		return
 	}
 
-	var posts []core.Post
-	if q != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
-		defer cancel()
+	// offset pagination is still supported for older clients
+	if qs.Get("offset") != "" {
+		app.readPostsByOffset(w, r, search.Text, limit, offset)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
 
+	var err error
+	var posts []core.Post
+	var next core.Cursor
+	if !search.IsZero() {
 		// search if requested
-		var err error
-		posts, err = app.storage.SearchPosts(ctx, q, limit, offset)
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
+		posts, next, err = app.storage.FilterPosts(ctx, search, cursor, limit)
 	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
-		defer cancel()
-
 		// else just read recent
-		var err error
+		posts, next, err = app.storage.ReadPostsAfter(ctx, cursor, limit)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"posts": posts, "next": nextCursor(next)})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) readPostsByOffset(w http.ResponseWriter, r *http.Request, q string, limit, offset int) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var err error
+	var posts []core.Post
+	if q != "" {
+		posts, err = app.storage.SearchPosts(ctx, q, limit, offset)
+	} else {
 		posts, err = app.storage.ReadPosts(ctx, limit, offset)
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
-	err := writeJSON(w, 200, envelope{"posts": posts})
+	err = writeJSON(w, 200, envelope{"posts": posts})
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 }
+
+// nextCursor encodes the cursor as JSON null once there are no more pages
+func nextCursor(cursor core.Cursor) interface{} {
+	if cursor.IsZero() {
+		return nil
+	}
+	return cursor.String()
+}
@@ -11,17 +11,24 @@ import (
 
 	"github.com/theandrew168/bloggulus/internal/api"
 	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
 	"github.com/theandrew168/bloggulus/internal/postgresql"
 	"github.com/theandrew168/bloggulus/internal/test"
 )
 
+type postsEnvelope struct {
+	Posts []core.Post `json:"posts"`
+	Next  *string     `json:"next"`
+}
+
 func TestHandleReadPost(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	post := test.CreateMockPost(storage, t)
 
@@ -63,8 +70,9 @@ func TestHandleReadPostNotFound(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/post/999999999", nil)
@@ -83,8 +91,9 @@ func TestHandleReadPosts(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	test.CreateMockPost(storage, t)
 
@@ -104,14 +113,14 @@ func TestHandleReadPosts(t *testing.T) {
 		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
 	}
 
-	var env map[string][]core.Post
+	var env postsEnvelope
 	err = json.Unmarshal(body, &env)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	got, ok := env["posts"]
-	if !ok {
+	got := env.Posts
+	if got == nil {
 		t.Fatalf("response missing key: %v", "posts")
 	}
 
@@ -125,8 +134,9 @@ func TestHandleReadPostsPagination(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	// create 5 posts to test with
 	test.CreateMockPost(storage, t)
@@ -163,14 +173,14 @@ func TestHandleReadPostsPagination(t *testing.T) {
 			t.Fatalf("want %v, got %v", 200, resp.StatusCode)
 		}
 
-		var env map[string][]core.Post
+		var env postsEnvelope
 		err = json.Unmarshal(body, &env)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		got, ok := env["posts"]
-		if !ok {
+		got := env.Posts
+		if got == nil {
 			t.Fatalf("response missing key: %v", "posts")
 		}
 
@@ -185,8 +195,9 @@ func TestHandleReadPostsSearch(t *testing.T) {
 	defer conn.Close()
 
 	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
 	logger := test.NewLogger()
-	app := api.NewApplication(storage, logger)
+	app := api.NewApplication(storage, reader, "", logger)
 
 	blog := test.CreateMockBlog(storage, t)
 	q := "python rust"
@@ -215,14 +226,14 @@ func TestHandleReadPostsSearch(t *testing.T) {
 		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
 	}
 
-	var env map[string][]core.Post
+	var env postsEnvelope
 	err = json.Unmarshal(body, &env)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	got, ok := env["posts"]
-	if !ok {
+	got := env.Posts
+	if got == nil {
 		t.Fatalf("response missing key: %v", "posts")
 	}
 
@@ -230,3 +241,279 @@ func TestHandleReadPostsSearch(t *testing.T) {
 		t.Fatalf("expected at least one matching post")
 	}
 }
+
+func TestHandleReadPostsCursor(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	// create 3 posts to test with
+	test.CreateMockPost(storage, t)
+	test.CreateMockPost(storage, t)
+	test.CreateMockPost(storage, t)
+
+	// follow the next cursor across two pages
+	var pages []postsEnvelope
+	url := "/post?limit=1"
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", url, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+		}
+
+		var env postsEnvelope
+		err = json.Unmarshal(body, &env)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(env.Posts) != 1 {
+			t.Fatalf("want %v, got %v", 1, len(env.Posts))
+		}
+
+		if env.Next == nil {
+			t.Fatalf("response missing next cursor")
+		}
+
+		pages = append(pages, env)
+		url = "/post?limit=1&cursor=" + *env.Next
+	}
+
+	if pages[0].Posts[0].ID == pages[1].Posts[0].ID {
+		t.Fatalf("next page repeated post %v", pages[0].Posts[0].ID)
+	}
+}
+
+func TestHandleReadPostsOffset(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	test.CreateMockPost(storage, t)
+	test.CreateMockPost(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/post?limit=1&offset=1", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env postsEnvelope
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(env.Posts) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(env.Posts))
+	}
+
+	// offset responses predate cursors and never include one
+	if env.Next != nil {
+		t.Fatalf("unexpected next cursor in offset response")
+	}
+}
+
+func TestHandleReadPostsTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	tag := test.CreateMockTag(storage, t)
+	blog := test.CreateMockBlog(storage, t)
+
+	// create 2 tagged posts and 1 that only shares the blog
+	tagged := make(map[int]bool)
+	for i := 0; i < 3; i++ {
+		post := test.NewMockPost(blog)
+		if i < 2 {
+			post.Body = "all about " + tag.Name
+		}
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 2 {
+			tagged[post.ID] = true
+		}
+	}
+
+	// tags can be filtered by any of their aliases
+	url := "/post?limit=1&tag=" + url.QueryEscape(tag.Aliases[0])
+	seen := make(map[int]bool)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", url, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+		}
+
+		var env postsEnvelope
+		err = json.Unmarshal(body, &env)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(env.Posts) != 1 {
+			t.Fatalf("want %v, got %v", 1, len(env.Posts))
+		}
+
+		post := env.Posts[0]
+		if !tagged[post.ID] || seen[post.ID] {
+			t.Fatalf("unexpected post %v", post.ID)
+		}
+		seen[post.ID] = true
+
+		// the last page has no next cursor
+		if i == 1 {
+			if env.Next != nil {
+				t.Fatalf("want no next cursor, got %v", *env.Next)
+			}
+			break
+		}
+
+		if env.Next == nil {
+			t.Fatalf("response missing next cursor")
+		}
+		url += "&cursor=" + *env.Next
+	}
+}
+
+func TestHandleReadPostsFilter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	blog := test.CreateMockBlog(storage, t)
+	other := test.CreateMockBlog(storage, t)
+	word := test.RandomWord(16)
+
+	// create 2 matching posts and 1 from another blog
+	for _, b := range []core.Blog{blog, blog, other} {
+		post := test.NewMockPost(b)
+		post.Body = word
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// filters can be given as parameters or within the search expression
+	paths := []string{
+		fmt.Sprintf("/post?q=%s&blog=%d&since=1d&sort=date", word, blog.ID),
+		fmt.Sprintf("/post?q=%s", url.QueryEscape(fmt.Sprintf("%s blog:%d since:1d sort:date", word, blog.ID))),
+	}
+
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: want %v, got %v", path, 200, resp.StatusCode)
+		}
+
+		var env postsEnvelope
+		err = json.Unmarshal(body, &env)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(env.Posts) != 2 {
+			t.Fatalf("%s: want %v, got %v", path, 2, len(env.Posts))
+		}
+		for _, post := range env.Posts {
+			if post.Blog.ID != blog.ID {
+				t.Fatalf("%s: want blog %v, got %v", path, blog.ID, post.Blog.ID)
+			}
+		}
+	}
+}
+
+func TestHandleReadPostsFilterInvalid(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	paths := []string{
+		"/post?tag=Go&offset=0",
+		"/post?q=tag:Go&offset=0",
+		"/post?blog=abc",
+		"/post?blog=-1",
+		"/post?since=soon",
+		"/post?until=later",
+		"/post?sort=oldest",
+	}
+
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 400 {
+			t.Fatalf("%s: want %v, got %v", path, 400, resp.StatusCode)
+		}
+	}
+}
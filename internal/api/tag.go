@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/validator"
+)
+
+// tag names and aliases are short words or phrases
+const maxTagNameLength = 64
+
+func (app *Application) HandleReadTag(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tag, err := app.storage.ReadTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"tag": tag})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadTags(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 200, "limit", "must be less than or equal to 200")
+
+	offset := readInt(qs, "offset", 0, v)
+	v.Check(offset >= 0, "offset", "must be positive")
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tags, err := app.storage.ReadTags(ctx, limit, offset)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"tags": tags})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateTag(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	input.Name = strings.TrimSpace(input.Name)
+	checkTagName(v, "name", input.Name)
+	for i := range input.Aliases {
+		input.Aliases[i] = strings.TrimSpace(input.Aliases[i])
+		checkTagName(v, "aliases", input.Aliases[i])
+	}
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tag := core.NewTag(input.Name, input.Aliases...)
+	err = app.storage.CreateTag(ctx, &tag)
+	if err != nil {
+		if errors.Is(err, core.ErrExist) {
+			app.conflictResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: created tag %d %q requested by %s\n", tag.ID, tag.Name, r.RemoteAddr)
+
+	w.Header().Set("Location", fmt.Sprintf("/api/tag/%d", tag.ID))
+	err = writeJSON(w, 201, envelope{"tag": tag})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// read the tag first so the audit log can describe it
+	tag, err := app.storage.ReadTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.DeleteTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: deleted tag %d %q requested by %s\n", tag.ID, tag.Name, r.RemoteAddr)
+
+	err = writeJSON(w, 200, envelope{"message": "tag successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateTagAlias(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	err = readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	checkTagName(v, "name", input.Name)
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.CreateTagAlias(ctx, id, input.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrExist):
+			app.conflictResponse(w, r)
+		case errors.Is(err, core.ErrNotExist):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	tag, err := app.storage.ReadTag(ctx, id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: added alias %q to tag %d %q requested by %s\n", input.Name, tag.ID, tag.Name, r.RemoteAddr)
+
+	err = writeJSON(w, 201, envelope{"tag": tag})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteTagAlias(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	alias, err := url.PathUnescape(chi.URLParam(r, "alias"))
+	if err != nil {
+		v.AddError("alias", "must be a valid path segment")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.DeleteTagAlias(ctx, id, alias)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tag, err := app.storage.ReadTag(ctx, id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: removed alias %q from tag %d %q requested by %s\n", alias, tag.ID, tag.Name, r.RemoteAddr)
+
+	err = writeJSON(w, 200, envelope{"tag": tag})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func checkTagName(v *validator.Validator, key, name string) {
+	v.Check(name != "", key, "must not be empty")
+	v.Check(len(name) <= maxTagNameLength, key, fmt.Sprintf("must not be more than %d bytes long", maxTagNameLength))
+}
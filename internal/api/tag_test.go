@@ -0,0 +1,405 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestHandleReadTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	tag := test.CreateMockTag(storage, t)
+
+	url := fmt.Sprintf("/tag/%d", tag.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", url, nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string]core.Tag
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["tag"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "tag")
+	}
+
+	if got.ID != tag.ID {
+		t.Fatalf("want %v, got %v", tag.ID, got.ID)
+	}
+	if len(got.Aliases) != len(tag.Aliases) {
+		t.Fatalf("want %v, got %v", tag.Aliases, got.Aliases)
+	}
+}
+
+func TestHandleReadTagNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/tag/999999999", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleReadTags(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, "", logger)
+
+	test.CreateMockTag(storage, t)
+	test.CreateMockTag(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/tag?limit=2", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.Tag
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["tags"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "tags")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(got))
+	}
+}
+
+func TestHandleCreateTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	tag := test.NewMockTag()
+
+	input := fmt.Sprintf(`{"name": %q, "aliases": [%q]}`, tag.Name, tag.Aliases[0])
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/tag", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env map[string]core.Tag
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["tag"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "tag")
+	}
+
+	if got.Name != tag.Name {
+		t.Fatalf("want %v, got %v", tag.Name, got.Name)
+	}
+
+	location := fmt.Sprintf("/api/tag/%d", got.ID)
+	if resp.Header.Get("Location") != location {
+		t.Fatalf("want %v, got %v", location, resp.Header.Get("Location"))
+	}
+
+	// creating the same tag again should conflict
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/tag", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateTagInvalid(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	inputs := []string{
+		`{"name": ""}`,
+		`{"name": "   "}`,
+		fmt.Sprintf(`{"name": %q}`, test.RandomWord(65)),
+		fmt.Sprintf(`{"name": %q, "aliases": [""]}`, test.RandomWord(16)),
+	}
+
+	for _, input := range inputs {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/tag", strings.NewReader(input))
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 400 {
+			t.Fatalf("want %v, got %v for %s", 400, resp.StatusCode, input)
+		}
+	}
+}
+
+func TestHandleCreateTagUnauthorized(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	input := fmt.Sprintf(`{"name": %q}`, test.RandomWord(16))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/tag", strings.NewReader(input))
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 401 {
+		t.Fatalf("want %v, got %v", 401, resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	tag := test.CreateMockTag(storage, t)
+
+	url := fmt.Sprintf("/tag/%d", tag.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	_, err := storage.ReadTag(context.Background(), tag.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted tag should not exist")
+	}
+
+	// deleting it again should not find anything
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleCreateTagAlias(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	tag := test.CreateMockTag(storage, t)
+	alias := test.RandomWord(16)
+
+	url := fmt.Sprintf("/tag/%d/alias", tag.ID)
+	input := fmt.Sprintf(`{"name": %q}`, alias)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", url, strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env map[string]core.Tag
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := env["tag"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "tag")
+	}
+
+	if len(got.Aliases) != len(tag.Aliases)+1 {
+		t.Fatalf("want %v aliases, got %v", len(tag.Aliases)+1, got.Aliases)
+	}
+
+	// adding the same alias again should conflict
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", url, strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 409 {
+		t.Fatalf("want %v, got %v", 409, resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteTagAlias(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	tag := test.NewMockTag()
+	tag.Aliases = []string{"Ruby " + test.RandomWord(16)}
+	err := storage.CreateTag(context.Background(), &tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// aliases may contain spaces so they are escaped in the path
+	path := fmt.Sprintf("/tag/%d/alias/%s", tag.ID, url.PathEscape(tag.Aliases[0]))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", path, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	got, err := storage.ReadTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Aliases) != 0 {
+		t.Fatalf("want no aliases, got %v", got.Aliases)
+	}
+
+	// removing it again should not find anything
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", path, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
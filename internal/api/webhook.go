@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/validator"
+)
+
+// limits on webhook settings
+const (
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 256
+	maxWebhookQueryLength  = 256
+)
+
+func (app *Application) HandleReadWebhook(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	webhook, err := app.storage.ReadWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"webhook": webhook})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadWebhooks(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 200, "limit", "must be less than or equal to 200")
+
+	offset := readInt(qs, "offset", 0, v)
+	v.Check(offset >= 0, "offset", "must be positive")
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	webhooks, err := app.storage.ReadWebhooks(ctx, limit, offset)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"webhooks": webhooks})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Tags   []string `json:"tags"`
+		Query  string   `json:"query"`
+	}
+
+	err := readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestMessageResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.URL != "", "url", "must be provided")
+	v.Check(validator.IsURL(input.URL), "url", "must be a valid URL")
+	if input.Secret != "" {
+		v.Check(len(input.Secret) >= minWebhookSecretLength, "secret", fmt.Sprintf("must be at least %d bytes long", minWebhookSecretLength))
+		v.Check(len(input.Secret) <= maxWebhookSecretLength, "secret", fmt.Sprintf("must not be more than %d bytes long", maxWebhookSecretLength))
+	}
+	for i := range input.Tags {
+		input.Tags[i] = strings.TrimSpace(input.Tags[i])
+		checkTagName(v, "tags", input.Tags[i])
+	}
+	input.Query = strings.TrimSpace(input.Query)
+	v.Check(len(input.Query) <= maxWebhookQueryLength, "query", fmt.Sprintf("must not be more than %d bytes long", maxWebhookQueryLength))
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	// generate a secret if one wasn't given
+	secret := input.Secret
+	if secret == "" {
+		secret, err = core.NewWebhookSecret()
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	webhook := core.NewWebhook(input.URL, secret, input.Tags, input.Query)
+	err = app.storage.CreateWebhook(ctx, &webhook)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: created webhook %d %q requested by %s\n", webhook.ID, webhook.URL, r.RemoteAddr)
+
+	// the secret is only ever shown here
+	w.Header().Set("Location", fmt.Sprintf("/api/webhook/%d", webhook.ID))
+	err = writeJSON(w, 201, envelope{"webhook": webhook, "secret": secret})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// read the webhook first so the audit log can describe it
+	webhook, err := app.storage.ReadWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.storage.DeleteWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("audit: deleted webhook %d %q requested by %s\n", webhook.ID, webhook.URL, r.RemoteAddr)
+
+	err = writeJSON(w, 200, envelope{"message": "webhook successfully deleted"})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleReadWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		v.AddError("id", "must be an integer")
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	v.Check(id >= 0, "id", "must be positive")
+
+	qs := r.URL.Query()
+
+	limit := readInt(qs, "limit", 20, v)
+	v.Check(limit >= 0, "limit", "must be positive")
+	v.Check(limit <= 200, "limit", "must be less than or equal to 200")
+
+	offset := readInt(qs, "offset", 0, v)
+	v.Check(offset >= 0, "offset", "must be positive")
+
+	if !v.Valid() {
+		app.badRequestResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// distinguish a missing webhook from one without deliveries
+	_, err = app.storage.ReadWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	deliveries, err := app.storage.ReadWebhookDeliveries(ctx, id, limit, offset)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeJSON(w, 200, envelope{"deliveries": deliveries})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,247 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/api"
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestHandleCreateWebhook(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	// only posts with a random tag so that no real posts are sent
+	tag := test.RandomString(16)
+	input := fmt.Sprintf(`{"url": "http://127.0.0.1:1/hook", "tags": [%q], "query": "postgres"}`, tag)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(input))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 201 {
+		t.Fatalf("want %v, got %v", 201, resp.StatusCode)
+	}
+
+	var env struct {
+		Webhook map[string]interface{} `json:"webhook"`
+		Secret  string                 `json:"secret"`
+	}
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret is generated and only shown once
+	if env.Secret == "" {
+		t.Fatal("response should include the generated secret")
+	}
+	if _, ok := env.Webhook["secret"]; ok {
+		t.Fatal("webhook should not include its secret")
+	}
+
+	id := int(env.Webhook["id"].(float64))
+	defer storage.DeleteWebhook(context.Background(), id)
+
+	location := fmt.Sprintf("/api/webhook/%d", id)
+	if resp.Header.Get("Location") != location {
+		t.Fatalf("want %v, got %v", location, resp.Header.Get("Location"))
+	}
+
+	webhook, err := storage.ReadWebhook(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if webhook.Secret != env.Secret {
+		t.Fatalf("want %v, got %v", env.Secret, webhook.Secret)
+	}
+	if webhook.Query != "postgres" || len(webhook.Tags) != 1 || webhook.Tags[0] != tag {
+		t.Fatalf("want filter %v %q, got %v %q", []string{tag}, "postgres", webhook.Tags, webhook.Query)
+	}
+}
+
+func TestHandleCreateWebhookInvalid(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	inputs := []string{
+		`{"url": ""}`,
+		`{"url": "ftp://example.com/hook"}`,
+		`{"url": "https://example.com/hook", "secret": "short"}`,
+		`{"url": "https://example.com/hook", "tags": [""]}`,
+		fmt.Sprintf(`{"url": "https://example.com/hook", "query": %q}`, test.RandomString(257)),
+	}
+
+	for _, input := range inputs {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/webhook", strings.NewReader(input))
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 400 {
+			t.Fatalf("want %v, got %v for %s", 400, resp.StatusCode, input)
+		}
+	}
+}
+
+func TestHandleCreateWebhookUnauthorized(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	input := `{"url": "https://example.com/hook"}`
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(input))
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 401 {
+		t.Fatalf("want %v, got %v", 401, resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteWebhook(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	webhook := test.CreateMockWebhook(storage, t)
+
+	url := fmt.Sprintf("/webhook/%d", webhook.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	// deleting it again should not be found
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleReadWebhookDeliveries(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	reader := feed.NewMockReader(test.NewMockBlog(), nil, "")
+	token := test.RandomString(32)
+	logger := test.NewLogger()
+	app := api.NewApplication(storage, reader, token, logger)
+
+	webhook := test.CreateMockWebhook(storage, t)
+	post := test.CreateMockPost(storage, t)
+
+	delivery := core.NewWebhookDelivery(webhook.ID, post.ID, []byte(`{"event": "post.created"}`))
+	err := storage.CreateWebhookDelivery(context.Background(), &delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := fmt.Sprintf("/webhook/%d/delivery", webhook.ID)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", url, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	var env map[string][]core.WebhookDelivery
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, ok := env["deliveries"]
+	if !ok {
+		t.Fatalf("response missing key: %v", "deliveries")
+	}
+
+	if len(deliveries) != 1 || deliveries[0].ID != delivery.ID {
+		t.Fatalf("want %v, got %v", []core.WebhookDelivery{delivery}, deliveries)
+	}
+	if deliveries[0].Status != core.DeliveryPending {
+		t.Fatalf("want %v, got %v", core.DeliveryPending, deliveries[0].Status)
+	}
+
+	// a missing webhook has no deliveries to show
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/webhook/999999999/delivery", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
@@ -0,0 +1,531 @@
+// Package article extracts the main content of a web page in a manner
+// similar to Mozilla's Readability: obvious clutter is pruned, <article>
+// and <main> elements are given priority, and otherwise each block of the
+// page is scored based on the paragraphs it contains.
+package article
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Article is the main content of a page
+type Article struct {
+	// plain text (suitable for indexing)
+	Text string
+	// sanitized HTML (suitable for display)
+	HTML string
+}
+
+// content shorter than this isn't trusted to be the article
+var minArticleLength = 140
+
+var (
+	// class / id values that indicate clutter
+	unlikelyPattern = regexp.MustCompile(`(?i)-ad-|agegate|banner|breadcrumb|combx|comment|community|consent|cookie|disqus|extra|footer|gdpr|header|legends|menu|newsletter|pager|pagination|popup|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscri|supplemental`)
+	// class / id values that save an otherwise unlikely element
+	maybePattern = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	// class / id values that make an element more or less likely to be the content
+	positivePattern = regexp.MustCompile(`(?i)article|blog|body|content|entry|h-entry|hentry|main|page|post|story|text`)
+	negativePattern = regexp.MustCompile(`(?i)-ad-|banner|combx|comment|com-|consent|contact|cookie|foot|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// elements that never contain article content
+var removeTags = map[atom.Atom]bool{
+	atom.Aside:    true,
+	atom.Button:   true,
+	atom.Canvas:   true,
+	atom.Dialog:   true,
+	atom.Embed:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Header:   true,
+	atom.Iframe:   true,
+	atom.Input:    true,
+	atom.Link:     true,
+	atom.Meta:     true,
+	atom.Nav:      true,
+	atom.Noscript: true,
+	atom.Object:   true,
+	atom.Script:   true,
+	atom.Select:   true,
+	atom.Style:    true,
+	atom.Svg:      true,
+	atom.Template: true,
+	atom.Textarea: true,
+}
+
+// elements that start a new line of text
+var blockTags = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Blockquote: true,
+	atom.Br:         true,
+	atom.Dd:         true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Figcaption: true,
+	atom.Figure:     true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Hr:         true,
+	atom.Li:         true,
+	atom.Main:       true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Table:      true,
+	atom.Td:         true,
+	atom.Th:         true,
+	atom.Tr:         true,
+	atom.Ul:         true,
+}
+
+var policy = bluemonday.UGCPolicy()
+
+// Extract finds the main content of an HTML page. Relative links within
+// the content are resolved against the page's URL.
+func Extract(r io.Reader, pageURL string) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, err
+	}
+
+	prune(doc, true)
+
+	root := findContent(doc)
+	if root == nil {
+		return Article{}, nil
+	}
+
+	return render(root, pageURL)
+}
+
+// Fragment converts HTML that is already known to be the main content
+// (such as the full text included in a feed entry) without searching it.
+// Relative links within the content are resolved against the page's URL.
+func Fragment(r io.Reader, pageURL string) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, err
+	}
+
+	prune(doc, false)
+
+	root := findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+	if root == nil {
+		return Article{}, nil
+	}
+
+	return render(root, pageURL)
+}
+
+func render(root *html.Node, pageURL string) (Article, error) {
+	base, err := url.Parse(pageURL)
+	if err == nil {
+		resolveURLs(root, base)
+	}
+
+	var buf bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		err = html.Render(&buf, c)
+		if err != nil {
+			return Article{}, err
+		}
+	}
+
+	article := Article{
+		Text: textContent(root),
+		HTML: strings.TrimSpace(policy.Sanitize(buf.String())),
+	}
+	return article, nil
+}
+
+// prune removes elements that never hold content along with (if clutter is
+// set) those that are unlikely to be part of it
+func prune(n *html.Node, clutter bool) {
+	var next *html.Node
+	for c := n.FirstChild; c != nil; c = next {
+		next = c.NextSibling
+
+		if c.Type == html.CommentNode {
+			n.RemoveChild(c)
+			continue
+		}
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		if removeTags[c.DataAtom] || isHidden(c) || (clutter && isUnlikely(c)) {
+			n.RemoveChild(c)
+			continue
+		}
+
+		// preformatted text is kept exactly as written
+		if c.DataAtom == atom.Pre {
+			continue
+		}
+
+		prune(c, clutter)
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+func isUnlikely(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main, atom.A, atom.Code, atom.Pre:
+		return false
+	}
+
+	match := attr(n, "class") + " " + attr(n, "id")
+	if attr(n, "role") == "complementary" || attr(n, "role") == "dialog" {
+		return true
+	}
+
+	return unlikelyPattern.MatchString(match) && !maybePattern.MatchString(match)
+}
+
+// findContent prefers an explicit <article>, then the best scoring block
+// (within <main> if present) and finally falls back to <main> or <body>.
+func findContent(doc *html.Node) *html.Node {
+	body := findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+	if body == nil {
+		return nil
+	}
+
+	// a single substantial <article> is almost certainly the content
+	var best *html.Node
+	bestLength := 0
+	for _, n := range findAll(body, func(n *html.Node) bool { return n.DataAtom == atom.Article }) {
+		length := len(textContent(n))
+		if length > bestLength {
+			best = n
+			bestLength = length
+		}
+	}
+	if best != nil && bestLength >= minArticleLength {
+		return best
+	}
+
+	scope := body
+	mainNode := findFirst(body, func(n *html.Node) bool {
+		return n.DataAtom == atom.Main || attr(n, "role") == "main"
+	})
+	if mainNode != nil && len(textContent(mainNode)) >= minArticleLength {
+		scope = mainNode
+	}
+
+	top := topCandidate(scope)
+	if top == nil {
+		return scope
+	}
+
+	return top
+}
+
+// topCandidate scores each paragraph's ancestors and returns the best one
+// along with any siblings that look like they belong to it.
+func topCandidate(scope *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	paragraphs := findAll(scope, isParagraph)
+	for _, p := range paragraphs {
+		text := textContent(p)
+		if len(text) < 25 {
+			continue
+		}
+
+		// one point for the paragraph itself, one per comma, and
+		// another for every 100 characters (up to 3)
+		score := 1.0
+		score += float64(strings.Count(text, ","))
+		score += math.Min(float64(len(text)/100), 3)
+
+		parent := p.Parent
+		addScore(parent, score)
+		if parent != nil && parent != scope {
+			addScore(parent.Parent, score/2)
+		}
+	}
+
+	var top *html.Node
+	topScore := 0.0
+	for _, n := range candidates {
+		// content that is mostly links is likely navigation
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > topScore {
+			top = n
+			topScore = scores[n]
+		}
+	}
+
+	if top == nil || top.Parent == nil {
+		return top
+	}
+
+	// gather up related siblings (such as an intro or trailing paragraphs)
+	threshold := math.Max(10, topScore*0.2)
+	var content []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top {
+			content = append(content, s)
+			continue
+		}
+		if s.Type != html.ElementNode {
+			continue
+		}
+
+		if score, ok := scores[s]; ok && score >= threshold {
+			content = append(content, s)
+			continue
+		}
+
+		if s.DataAtom == atom.P {
+			text := textContent(s)
+			density := linkDensity(s)
+			if len(text) > 80 && density < 0.25 {
+				content = append(content, s)
+			}
+		}
+	}
+
+	if len(content) == 1 {
+		return top
+	}
+
+	wrapper := &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	}
+	for _, n := range content {
+		n.Parent.RemoveChild(n)
+		wrapper.AppendChild(n)
+	}
+	return wrapper
+}
+
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += 10
+	case atom.Div, atom.Section:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativePattern.MatchString(value) {
+			score -= 25
+		}
+		if positivePattern.MatchString(value) {
+			score += 25
+		}
+	}
+
+	return score
+}
+
+// isParagraph reports whether a node holds a paragraph's worth of text,
+// including divs that are used like paragraphs.
+func isParagraph(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockTags[c.DataAtom] && c.DataAtom != atom.Br {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func linkDensity(n *html.Node) float64 {
+	length := len(textContent(n))
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	for _, a := range findAll(n, func(n *html.Node) bool { return n.DataAtom == atom.A }) {
+		linkLength += len(textContent(a))
+	}
+
+	return float64(linkLength) / float64(length)
+}
+
+func resolveURLs(n *html.Node, base *url.URL) {
+	if n.Type == html.ElementNode {
+		for i, a := range n.Attr {
+			if a.Key != "href" && a.Key != "src" {
+				continue
+			}
+
+			u, err := base.Parse(strings.TrimSpace(a.Val))
+			if err != nil {
+				continue
+			}
+			n.Attr[i].Val = u.String()
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		resolveURLs(c, base)
+	}
+}
+
+// textContent renders a node as plain text with one line per block.
+// Whitespace is collapsed everywhere except within preformatted text.
+func textContent(n *html.Node) string {
+	var w textWriter
+	w.write(n, false)
+	w.flush()
+	return strings.Join(w.lines, "\n")
+}
+
+type textWriter struct {
+	lines []string
+	line  strings.Builder
+	// current line contains preformatted text
+	pre bool
+}
+
+func (w *textWriter) write(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if !pre {
+			w.line.WriteString(n.Data)
+			return
+		}
+
+		for i, part := range strings.Split(n.Data, "\n") {
+			if i > 0 {
+				w.flush()
+			}
+			w.pre = true
+			w.line.WriteString(part)
+		}
+		return
+	case html.ElementNode:
+		if n.DataAtom == atom.Pre {
+			pre = true
+		}
+		if blockTags[n.DataAtom] {
+			w.flush()
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.write(c, pre)
+	}
+
+	if n.Type == html.ElementNode && blockTags[n.DataAtom] {
+		w.flush()
+	}
+}
+
+func (w *textWriter) flush() {
+	line := w.line.String()
+	if w.pre {
+		line = strings.TrimRight(line, " \t\r")
+	} else {
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+	}
+
+	if strings.TrimSpace(line) != "" {
+		w.lines = append(w.lines, line)
+	}
+
+	w.line.Reset()
+	w.pre = false
+}
+
+var spacePattern = regexp.MustCompile(`\s+`)
+
+func findFirst(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && match(c) {
+			return c
+		}
+		if found := findFirst(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && match(c) {
+			found = append(found, c)
+		}
+		found = append(found, findAll(c, match)...)
+	}
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package article_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/article"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestExtract(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no test pages found")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := article.Extract(f, "https://example.com/posts/"+name+"/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden(t, filepath.Join("testdata", name+".text.golden"), got.Text)
+			golden(t, filepath.Join("testdata", name+".html.golden"), got.HTML)
+		})
+	}
+}
+
+func TestExtractEmpty(t *testing.T) {
+	got, err := article.Extract(strings.NewReader(""), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Text != "" || got.HTML != "" {
+		t.Fatalf("want empty article, got %+v", got)
+	}
+}
+
+func TestExtractKeepsCode(t *testing.T) {
+	page := `<html><body><article>
+		<p>The following snippet prints a greeting, which is about as simple as a program can get.</p>
+		<pre><code>func main() {
+	fmt.Println("hello")
+}</code></pre>
+		<p>Run it with <code>go run</code> and the greeting will be printed to standard output.</p>
+	</article></body></html>`
+
+	got, err := article.Extract(strings.NewReader(page), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "func main() {\n\tfmt.Println(\"hello\")\n}"
+	if !strings.Contains(got.Text, want) {
+		t.Errorf("text is missing code block: %q", got.Text)
+	}
+	if !strings.Contains(got.Text, "Run it with go run and") {
+		t.Errorf("text is missing inline code: %q", got.Text)
+	}
+}
+
+func TestFragment(t *testing.T) {
+	content := `<p>See <a href="/about">the about page</a> for more.</p><script>alert("hi")</script><div class="related">Nothing is pruned from feed content.</div>`
+
+	got, err := article.Fragment(strings.NewReader(content), "https://example.com/posts/hello/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantText := "See the about page for more.\nNothing is pruned from feed content."
+	if got.Text != wantText {
+		t.Errorf("want text %q, got %q", wantText, got.Text)
+	}
+
+	wantHTML := `<p>See <a href="https://example.com/about" rel="nofollow">the about page</a> for more.</p><div>Nothing is pruned from feed content.</div>`
+	if got.HTML != wantHTML {
+		t.Errorf("want HTML %q, got %q", wantHTML, got.HTML)
+	}
+}
+
+func golden(t *testing.T, path string, got string) {
+	t.Helper()
+
+	if *update {
+		err := os.WriteFile(path, []byte(got+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got+"\n" != string(want) {
+		t.Errorf("output does not match %s (run with -update to regenerate):\n%s", path, got)
+	}
+}
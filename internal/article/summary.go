@@ -0,0 +1,92 @@
+package article
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// summaries stop growing once they reach the min length and are
+	// cut short (at a word boundary) if they would pass the max length
+	minSummaryLength = 160
+	maxSummaryLength = 300
+
+	// lines of prose end like sentences do (unlike headings or code)
+	proseLinePattern   = regexp.MustCompile(`[.!?]["'”’)\]]*$`)
+	sentenceEndPattern = regexp.MustCompile(`[.!?]["'”’)\]]*\s+`)
+)
+
+// Summarize returns the first few sentences of an article's text
+func Summarize(text string) string {
+	var prose []string
+	var first string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if first == "" {
+			first = line
+		}
+		if proseLinePattern.MatchString(line) {
+			prose = append(prose, line)
+		}
+	}
+
+	// without any prose, the start of the text will have to do
+	if len(prose) == 0 {
+		return truncate(first, maxSummaryLength)
+	}
+
+	var summary string
+	for _, sentence := range sentences(strings.Join(prose, " ")) {
+		if summary != "" && len(summary)+1+len(sentence) > maxSummaryLength {
+			break
+		}
+
+		if summary != "" {
+			summary += " "
+		}
+		summary += sentence
+
+		if len(summary) >= minSummaryLength {
+			break
+		}
+	}
+
+	return truncate(summary, maxSummaryLength)
+}
+
+func sentences(text string) []string {
+	var found []string
+	start := 0
+	for _, loc := range sentenceEndPattern.FindAllStringIndex(text, -1) {
+		found = append(found, strings.TrimSpace(text[start:loc[1]]))
+		start = loc[1]
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		found = append(found, rest)
+	}
+	return found
+}
+
+// truncate shortens text to at most n bytes (plus an ellipsis), breaking
+// at a word boundary where possible
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+
+	cut := strings.LastIndex(text[:n], " ")
+	if cut <= 0 {
+		cut = n
+	}
+
+	// don't split a multi-byte character
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return strings.TrimRight(text[:cut], " ,;:") + "…"
+}
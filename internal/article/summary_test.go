@@ -0,0 +1,39 @@
+package article_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/article"
+)
+
+func TestSummarize(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Hello world.", "Hello world."},
+		{
+			"A heading\nThe first sentence is here. The second one follows it! Does a third?",
+			"The first sentence is here. The second one follows it! Does a third?",
+		},
+		{
+			"This opening sentence is long enough that it goes on and on about nothing in particular for quite a while. " +
+				"So is this second sentence, which also rambles for a bit in order to reach the minimum length. " +
+				"This third sentence should not be included.",
+			"This opening sentence is long enough that it goes on and on about nothing in particular for quite a while. " +
+				"So is this second sentence, which also rambles for a bit in order to reach the minimum length.",
+		},
+		{"func main() {\n\tfmt.Println(\"hi\")\n}", "func main() {"},
+		{long, strings.TrimSpace(long[:300]) + "…"},
+	}
+
+	for _, test := range tests {
+		if got := article.Summarize(test.text); got != test.want {
+			t.Errorf("article.Summarize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package canonical normalizes URLs so that each blog and post can be
+// recognized regardless of how a feed happened to link to it. Canonical
+// URLs are only compared, never fetched (the original link may not work
+// over https, for example).
+package canonical
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// query params added by analytics and newsletter tools
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+}
+
+// URL returns the canonical form of an absolute http(s) URL:
+//
+//   - the scheme and host are lowercased
+//   - default ports are removed and http is upgraded to https
+//   - trailing slashes are removed from the path
+//   - tracking params (utm_*, fbclid, etc) are removed and the rest sorted
+//   - the fragment is removed
+//
+// URLs that can't be canonicalized are returned unchanged.
+func URL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return rawURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+
+	// only upgrade to https when no other port was requested
+	if port == "" || (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		scheme = "https"
+		port = ""
+	}
+
+	u.Scheme = scheme
+	u.Host = host
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u.String()
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
+
+// Link looks for a <link rel="canonical"> within the head of an HTML page
+// and returns it (resolved against the page's URL). Links pointing to a
+// different site than the page itself are ignored so that a page can't
+// claim another's posts.
+func Link(pageURL string, r io.Reader) (string, bool) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return "", false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			switch token.Data {
+			case "body":
+				// canonical links only belong in the head
+				return "", false
+			case "link":
+				href, ok := canonicalHref(token)
+				if !ok {
+					continue
+				}
+
+				link, err := base.Parse(href)
+				if err != nil || !sameSite(base, link) {
+					return "", false
+				}
+
+				return link.String(), true
+			}
+		case html.EndTagToken:
+			if z.Token().Data == "head" {
+				return "", false
+			}
+		}
+	}
+}
+
+func canonicalHref(token html.Token) (string, bool) {
+	var rel, href string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "rel":
+			rel = attr.Val
+		case "href":
+			href = strings.TrimSpace(attr.Val)
+		}
+	}
+
+	for _, value := range strings.Fields(rel) {
+		if strings.EqualFold(value, "canonical") && href != "" {
+			return href, true
+		}
+	}
+
+	return "", false
+}
+
+// SameSite reports whether two URLs belong to the same site, treating
+// "www." and bare hosts as equivalent.
+func SameSite(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return sameSite(ua, ub)
+}
+
+func sameSite(a, b *url.URL) bool {
+	hostA := strings.TrimPrefix(strings.ToLower(a.Hostname()), "www.")
+	hostB := strings.TrimPrefix(strings.ToLower(b.Hostname()), "www.")
+	return hostA == hostB
+}
@@ -0,0 +1,81 @@
+package canonical_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/canonical"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com/a", "https://example.com/a"},
+		{"http://example.com/a/", "https://example.com/a"},
+		{"HTTPS://Example.COM/a", "https://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"http://example.com:80/a", "https://example.com/a"},
+		{"http://example.com:8080/a/", "http://example.com:8080/a"},
+		{"https://example.com.", "https://example.com/"},
+		{"https://example.com", "https://example.com/"},
+		{"https://example.com/a#comments", "https://example.com/a"},
+		{"https://example.com/a?utm_source=rss&utm_medium=feed", "https://example.com/a"},
+		{"https://example.com/a?p=2&fbclid=abc&id=1", "https://example.com/a?id=1&p=2"},
+		{"https://example.com/A/B", "https://example.com/A/B"},
+
+		// left alone
+		{"", ""},
+		{"/relative/path", "/relative/path"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+	}
+
+	for _, test := range tests {
+		if got := canonical.URL(test.input); got != test.want {
+			t.Errorf("canonical.URL(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		page string
+		want string
+		ok   bool
+	}{
+		{`<head><link rel="canonical" href="https://example.com/post/"></head>`, "https://example.com/post/", true},
+		{`<head><link rel="canonical" href="/post?utm_source=x"></head>`, "http://example.com/post?utm_source=x", true},
+		{`<head><link href="https://www.example.com/post" rel="Canonical" /></head>`, "https://www.example.com/post", true},
+		{`<head><link rel="stylesheet" href="/style.css"></head>`, "", false},
+		{`<head><link rel="canonical" href="https://other.com/post"></head>`, "", false},
+		{`<head></head><body><link rel="canonical" href="/post"></body>`, "", false},
+		{`not html at all`, "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := canonical.Link("http://example.com/post?utm_source=rss", strings.NewReader(test.page))
+		if got != test.want || ok != test.ok {
+			t.Errorf("canonical.Link(%q) = %q, %v", test.page, got, ok)
+		}
+	}
+}
+
+func TestSameSite(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{"https://example.com/a", "http://example.com/b", true},
+		{"https://www.example.com/a", "https://example.com/a", true},
+		{"https://example.com/a", "https://example.org/a", false},
+		{"https://blog.example.com/a", "https://example.com/a", false},
+	}
+
+	for _, test := range tests {
+		if got := canonical.SameSite(test.a, test.b); got != test.want {
+			t.Errorf("canonical.SameSite(%q, %q) = %v", test.a, test.b, got)
+		}
+	}
+}
@@ -4,17 +4,53 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 var (
 	defaultPort = "5000"
+
+	defaultSyncConcurrency     = 8
+	defaultSyncHostConcurrency = 2
+	defaultSyncHostDelay       = 1 * time.Second
+
+	defaultSMTPPort       = "587"
+	defaultDigestInterval = 7 * 24 * time.Hour
 )
 
+// Duration allows values such as "500ms" or "2s" in the config file
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
 type Config struct {
 	DatabaseURI string `toml:"database_uri"`
 	Port        string `toml:"port"`
+	AdminToken  string `toml:"admin_token"`
+
+	SyncConcurrency     int      `toml:"sync_concurrency"`
+	SyncHostConcurrency int      `toml:"sync_host_concurrency"`
+	SyncHostDelay       Duration `toml:"sync_host_delay"`
+
+	SMTPHost     string `toml:"smtp_host"`
+	SMTPPort     string `toml:"smtp_port"`
+	SMTPUsername string `toml:"smtp_username"`
+	SMTPPassword string `toml:"smtp_password"`
+	SMTPFrom     string `toml:"smtp_from"`
+
+	// digests are only sent if there is someone to send them to
+	DigestTo       []string `toml:"digest_to"`
+	DigestInterval Duration `toml:"digest_interval"`
+	DigestTags     []string `toml:"digest_tags"`
+	DigestQuery    string   `toml:"digest_query"`
 }
 
 func Read(data string) (Config, error) {
@@ -48,6 +84,11 @@ func Read(data string) (Config, error) {
 		"database_uri",
 	}
 
+	// digests can't be sent without a mail server
+	if len(cfg.DigestTo) > 0 {
+		required = append(required, "smtp_host", "smtp_from")
+	}
+
 	// gather missing values
 	missing := []string{}
 	for _, key := range required {
@@ -66,6 +107,21 @@ func Read(data string) (Config, error) {
 	if cfg.Port == "" {
 		cfg.Port = defaultPort
 	}
+	if cfg.SyncConcurrency <= 0 {
+		cfg.SyncConcurrency = defaultSyncConcurrency
+	}
+	if cfg.SyncHostConcurrency <= 0 {
+		cfg.SyncHostConcurrency = defaultSyncHostConcurrency
+	}
+	if _, ok := present["sync_host_delay"]; !ok {
+		cfg.SyncHostDelay.Duration = defaultSyncHostDelay
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = defaultSMTPPort
+	}
+	if cfg.DigestInterval.Duration <= 0 {
+		cfg.DigestInterval.Duration = defaultDigestInterval
+	}
 
 	return cfg, nil
 }
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// limits on account credentials (bcrypt ignores anything past 72 bytes)
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 32
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// Account is a reader who logs in to follow their own set of blogs.
+type Account struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+
+	// readonly (from database, after creation)
+	ID int `json:"id"`
+}
+
+// NewAccount creates an account with a hash of the given password.
+func NewAccount(username, password string) (Account, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return Account{}, err
+	}
+
+	account := Account{
+		Username:     username,
+		PasswordHash: string(hash),
+	}
+	return account, nil
+}
+
+// PasswordMatches reports whether password is the account's password.
+func (a Account) PasswordMatches(password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsUsername reports whether s only contains letters, digits, dashes and
+// underscores (other limits are checked separately).
+func IsUsername(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+		case r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+		case r == '-' || r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Usernames are unique (ignoring case).
+type AccountStorage interface {
+	CreateAccount(ctx context.Context, account *Account) error
+	ReadAccount(ctx context.Context, id int) (Account, error)
+	ReadAccountByUsername(ctx context.Context, username string) (Account, error)
+	// ReadAccountBySession finds the account logged in with an unexpired
+	// session (by the hash of its token)
+	ReadAccountBySession(ctx context.Context, hash string) (Account, error)
+}
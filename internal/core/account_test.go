@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func TestAccountPassword(t *testing.T) {
+	account, err := core.NewAccount("alice", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if account.PasswordHash == "correct horse" {
+		t.Fatal("password should be hashed")
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse", true},
+		{"Correct horse", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		got, err := account.PasswordMatches(test.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != test.want {
+			t.Fatalf("%q: want %v, got %v", test.password, test.want, got)
+		}
+	}
+}
+
+func TestIsUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{"alice", true},
+		{"Bob_Smith-42", true},
+		{"alice smith", false},
+		{"alice@example.com", false},
+		{"ålice", false},
+	}
+
+	for _, test := range tests {
+		got := core.IsUsername(test.username)
+		if got != test.want {
+			t.Fatalf("%q: want %v, got %v", test.username, test.want, got)
+		}
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	session, token, err := core.NewSession(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if session.AccountID != 42 {
+		t.Fatalf("want %v, got %v", 42, session.AccountID)
+	}
+
+	// only the hash of the token is kept
+	if session.Hash != core.HashSessionToken(token) {
+		t.Fatalf("want %v, got %v", core.HashSessionToken(token), session.Hash)
+	}
+	if session.Hash == token {
+		t.Fatal("session should not store its token")
+	}
+
+	_, other, err := core.NewSession(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token {
+		t.Fatal("session tokens should be unique")
+	}
+}
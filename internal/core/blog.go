@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Blog struct {
@@ -9,6 +10,14 @@ type Blog struct {
 	SiteURL string `json:"site_url"`
 	Title   string `json:"title"`
 
+	// HTTP cache validators from the most recent feed fetch
+	ETag         string `json:"-"`
+	LastModified string `json:"-"`
+
+	// sync scheduling (TTL is the feed's own refresh hint, if any)
+	TTL        time.Duration `json:"-"`
+	NextSyncAt time.Time     `json:"-"`
+
 	// readonly (from database, after creation)
 	ID int `json:"id"`
 }
@@ -22,8 +31,22 @@ func NewBlog(feedURL, siteURL, title string) Blog {
 	return blog
 }
 
+// BlogStats summarizes the posts that have been read from a blog.
+type BlogStats struct {
+	Blog
+
+	PostCount int        `json:"post_count"`
+	LastPost  *time.Time `json:"last_post"`
+}
+
 type BlogStorage interface {
 	CreateBlog(ctx context.Context, blog *Blog) error
 	ReadBlog(ctx context.Context, id int) (Blog, error)
 	ReadBlogs(ctx context.Context, limit, offset int) ([]Blog, error)
+	ReadBlogStats(ctx context.Context, limit, offset int) ([]BlogStats, error)
+	ReadFailingBlogs(ctx context.Context, limit, offset int) ([]Blog, error)
+	ReadBlogsDueForSync(ctx context.Context, afterID, limit int) ([]Blog, error)
+	UpdateBlog(ctx context.Context, blog Blog) error
+	UpdateBlogSchedule(ctx context.Context, blog Blog) error
+	DeleteBlog(ctx context.Context, id int) error
 }
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Cursor marks a position within an ordered list of posts. Listings are
+// ordered by (Updated, ID) while searches are ordered by (Rank, ID). The
+// zero value refers to the start of the list.
+type Cursor struct {
+	Updated time.Time `json:"u"`
+	Rank    float32   `json:"r,omitempty"`
+	ID      int       `json:"i"`
+}
+
+func (c Cursor) IsZero() bool {
+	return c == Cursor{}
+}
+
+// String encodes the cursor as an opaque, URL-safe token.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// ParseCursor decodes a token previously produced by Cursor.String.
+func ParseCursor(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{}, nil
+	}
+
+	js, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	err = json.Unmarshal(js, &c)
+	if err != nil || c.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,58 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func TestCursor(t *testing.T) {
+	tests := []core.Cursor{
+		{Updated: time.Date(2021, 11, 7, 12, 30, 15, 123456000, time.UTC), ID: 42},
+		{Rank: 0.1, ID: 7},
+		{Rank: 0, ID: 1},
+	}
+
+	for _, want := range tests {
+		got, err := core.ParseCursor(want.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Updated.Equal(want.Updated) || got.Rank != want.Rank || got.ID != want.ID {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	}
+}
+
+func TestCursorZero(t *testing.T) {
+	if s := (core.Cursor{}).String(); s != "" {
+		t.Fatalf("want empty token, got %q", s)
+	}
+
+	got, err := core.ParseCursor("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.IsZero() {
+		t.Fatalf("want zero cursor, got %v", got)
+	}
+}
+
+func TestCursorInvalid(t *testing.T) {
+	tests := []string{
+		"not a cursor",
+		"e30",      // {}
+		"eyJpIjoi", // {"i":"
+	}
+
+	for _, token := range tests {
+		_, err := core.ParseCursor(token)
+		if !errors.Is(err, core.ErrInvalidCursor) {
+			t.Fatalf("%q: want %v, got %v", token, core.ErrInvalidCursor, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Digest is an email listing the posts found since the previous one.
+// Since is the start of the window of posts that the digest covered.
+type Digest struct {
+	Since   time.Time `json:"since"`
+	SentAt  time.Time `json:"sent_at"`
+	PostIDs []int     `json:"post_ids"`
+
+	// readonly (from database, after creation)
+	ID int `json:"id"`
+}
+
+func NewDigest(since, sentAt time.Time, posts []Post) Digest {
+	postIDs := make([]int, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	digest := Digest{
+		Since:   since,
+		SentAt:  sentAt,
+		PostIDs: postIDs,
+	}
+	return digest
+}
+
+// Digests remember which posts they included so that no post is ever sent
+// twice (even if it changes after being sent).
+type DigestStorage interface {
+	CreateDigest(ctx context.Context, digest *Digest) error
+	// ReadLastDigest returns ErrNotExist if no digest has been sent yet
+	ReadLastDigest(ctx context.Context) (Digest, error)
+
+	// ReadUndigestedPosts reads the earliest posts matching a search that
+	// were first seen at or after since and haven't been in a digest (so
+	// that a limited read can be continued from the same since)
+	ReadUndigestedPosts(ctx context.Context, search SearchRequest, since time.Time, limit int) ([]Post, error)
+}
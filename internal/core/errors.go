@@ -12,4 +12,6 @@ var (
 
 	ErrRetry    = errors.New("core: retry storage operation")
 	ErrConflict = errors.New("core: conflict in storage operation")
+
+	ErrInvalidCursor = errors.New("core: invalid cursor")
 )
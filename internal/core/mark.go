@@ -0,0 +1,25 @@
+package core
+
+import (
+	"context"
+)
+
+// Each account keeps track of which posts it has read (by following them
+// from bloggulus) and which it has saved to read later.
+type PostMarkStorage interface {
+	// MarkPostRead returns ErrNotExist if the post doesn't exist (posts
+	// can be marked read more than once)
+	MarkPostRead(ctx context.Context, accountID, postID int) error
+
+	// SavePost returns ErrExist if the post is already saved and
+	// ErrNotExist if the post doesn't exist
+	SavePost(ctx context.Context, accountID, postID int) error
+	UnsavePost(ctx context.Context, accountID, postID int) error
+	ReadSavedPostsAfter(ctx context.Context, accountID int, cursor Cursor, limit int) ([]Post, Cursor, error)
+
+	// ReadPostMarks sets Read and Saved on each post for the account
+	ReadPostMarks(ctx context.Context, accountID int, posts []Post) error
+	// CountUnreadPosts counts the unread posts of each blog that the
+	// account is subscribed to (by blog ID)
+	CountUnreadPosts(ctx context.Context, accountID int) (map[int]int, error)
+}
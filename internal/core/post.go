@@ -5,24 +5,54 @@ import (
 	"time"
 )
 
+// Post is a single entry from a blog's feed. Posts are sorted by Updated,
+// which comes from the feed (falling back to when the post was published)
+// or is set to when the post was first seen if the feed doesn't date it.
 type Post struct {
 	// fields known upfront
 	URL     string    `json:"url"`
+	GUID    string    `json:"guid"`
 	Title   string    `json:"title"`
 	Updated time.Time `json:"updated"`
 	Blog    Blog      `json:"blog"`
 
+	// optional metadata from the feed (Published is nil if unknown)
+	Published *time.Time `json:"published"`
+	Author    string     `json:"author"`
+
+	// a few sentences describing the post
+	Summary string `json:"summary"`
+
 	// readonly (from database, after creation)
-	ID   int      `json:"id"`
-	Tags []string `json:"tags"`
+	ID        int       `json:"id"`
+	FirstSeen time.Time `json:"first_seen"`
+	Tags      []string  `json:"tags"`
+
+	// search results only: an HTML excerpt with matched terms in <mark>
+	Snippet string `json:"snippet,omitempty"`
 
-	// used in sync process
-	Body string `json:"-"`
+	// account feeds only: whether the account has read or saved the post
+	Read  bool `json:"read,omitempty"`
+	Saved bool `json:"saved,omitempty"`
+
+	// used in sync process (plain text for indexing and sanitized HTML)
+	Body       string `json:"-"`
+	BodyHTML   string `json:"-"`
+	BodySource string `json:"-"`
 }
 
+// where a post's body was read from
+const (
+	BodySourceFeed = "feed"
+	BodySourcePage = "page"
+)
+
+// NewPost creates a post identified by its URL. Callers that know the feed
+// entry's GUID (RSS guid or Atom id) should set it afterwards.
 func NewPost(url, title string, updated time.Time, blog Blog) Post {
 	post := Post{
 		URL:     url,
+		GUID:    url,
 		Title:   title,
 		Updated: updated,
 		Blog:    blog,
@@ -33,9 +63,31 @@ func NewPost(url, title string, updated time.Time, blog Blog) Post {
 type PostStorage interface {
 	CreatePost(ctx context.Context, post *Post) error
 	ReadPost(ctx context.Context, id int) (Post, error)
+	UpdatePost(ctx context.Context, post Post) error
+	UpdatePostGUID(ctx context.Context, id int, guid string) error
 	ReadPosts(ctx context.Context, limit, offset int) ([]Post, error)
 	ReadPostsByBlog(ctx context.Context, blogID int, limit, offset int) ([]Post, error)
 	SearchPosts(ctx context.Context, query string, limit, offset int) ([]Post, error)
+
+	// keyset variants of the above which also return the cursor for the
+	// next page (or the zero cursor when there are no more posts)
+	ReadPostsAfter(ctx context.Context, cursor Cursor, limit int) ([]Post, Cursor, error)
+	ReadPostsByBlogAfter(ctx context.Context, blogID int, cursor Cursor, limit int) ([]Post, Cursor, error)
+	ReadPostsByTagAfter(ctx context.Context, tagID int, cursor Cursor, limit int) ([]Post, Cursor, error)
+	SearchPostsAfter(ctx context.Context, query string, cursor Cursor, limit int) ([]Post, Cursor, error)
+
+	// ReadSubscribedPostsAfter pages through the posts from the blogs that
+	// an account is subscribed to
+	ReadSubscribedPostsAfter(ctx context.Context, accountID int, cursor Cursor, limit int) ([]Post, Cursor, error)
+
+	// FilterPosts pages through the posts matching a structured search
+	// (ordered by relevance or date as requested)
+	FilterPosts(ctx context.Context, search SearchRequest, cursor Cursor, limit int) ([]Post, Cursor, error)
+	// MatchPosts returns which of the given posts match a search
+	MatchPosts(ctx context.Context, search SearchRequest, postIDs []int) ([]int, error)
+
 	CountPosts(ctx context.Context) (int, error)
+	CountPostsByBlog(ctx context.Context, blogID int) (int, error)
 	CountSearchPosts(ctx context.Context, query string) (int, error)
+	CountFilteredPosts(ctx context.Context, search SearchRequest) (int, error)
 }
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// orders that search results can be sorted by
+const (
+	SortRelevance = "relevance"
+	SortDate      = "date"
+)
+
+// SearchRequest narrows posts down by their content, blog, tags and when
+// they were updated. Zero fields don't filter anything.
+type SearchRequest struct {
+	// free-text query (in websearch_to_tsquery syntax)
+	Text string
+	// posts from any of these blogs
+	BlogIDs []int
+	// posts with all of these tags (by name or alias)
+	Tags []string
+	// posts updated at or after Since and before Until
+	Since time.Time
+	Until time.Time
+	// SortRelevance (the default when searching text) or SortDate
+	Sort string
+}
+
+func (s SearchRequest) IsZero() bool {
+	return s.Text == "" && !s.HasFilters()
+}
+
+// HasFilters reports whether the request does more than search text.
+func (s SearchRequest) HasFilters() bool {
+	return len(s.BlogIDs) > 0 || len(s.Tags) > 0 || !s.Since.IsZero() || !s.Until.IsZero() || s.Sort != ""
+}
+
+// ByRelevance reports whether results are ordered by rank (instead of by
+// date). Only text searches have a rank to order by.
+func (s SearchRequest) ByRelevance() bool {
+	return s.Text != "" && s.Sort != SortDate
+}
+
+// ParseSearch parses a search box query. Besides free text it understands
+// a few operators:
+//
+//	blog:42          posts from a blog (by ID)
+//	tag:Go           posts with a tag (quoted if it has spaces)
+//	since:2022-01-31 posts updated on or after a date (or since:6m for
+//	                 the last six months, also d, w and y)
+//	until:2022-12-31 posts updated on or before a date
+//	sort:date        newest first rather than most relevant
+//
+// Operators with invalid values are treated as text.
+func ParseSearch(q string, now time.Time) SearchRequest {
+	var search SearchRequest
+	var text []string
+
+	for {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		var token string
+		token, q = nextSearchToken(q)
+		if !parseSearchOperator(&search, token, now) {
+			text = append(text, token)
+		}
+	}
+
+	search.Text = strings.Join(text, " ")
+	return search
+}
+
+// ParseSearchSince parses the start of a search's date range.
+func ParseSearchSince(s string, now time.Time) (time.Time, bool) {
+	t, _, ok := parseSearchTime(s, now)
+	return t, ok
+}
+
+// ParseSearchUntil parses the end of a search's date range. Dates include
+// the whole day.
+func ParseSearchUntil(s string, now time.Time) (time.Time, bool) {
+	t, isDate, ok := parseSearchTime(s, now)
+	if ok && isDate {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, ok
+}
+
+// IsSearchSort reports whether s is a valid sort order.
+func IsSearchSort(s string) bool {
+	return s == SortRelevance || s == SortDate
+}
+
+// nextSearchToken splits off the next whitespace separated token while
+// keeping quoted phrases together.
+func nextSearchToken(q string) (string, string) {
+	quoted := false
+	for i, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			return q[:i], q[i:]
+		}
+	}
+	return q, ""
+}
+
+func parseSearchOperator(search *SearchRequest, token string, now time.Time) bool {
+	key, value, ok := strings.Cut(token, ":")
+	if !ok {
+		return false
+	}
+
+	value = strings.Trim(value, `"`)
+	if value == "" {
+		return false
+	}
+
+	switch strings.ToLower(key) {
+	case "blog":
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return false
+		}
+		search.BlogIDs = append(search.BlogIDs, id)
+	case "tag":
+		search.Tags = append(search.Tags, value)
+	case "since":
+		since, ok := ParseSearchSince(value, now)
+		if !ok {
+			return false
+		}
+		search.Since = since
+	case "until":
+		until, ok := ParseSearchUntil(value, now)
+		if !ok {
+			return false
+		}
+		search.Until = until
+	case "sort":
+		value = strings.ToLower(value)
+		if !IsSearchSort(value) {
+			return false
+		}
+		search.Sort = value
+	default:
+		return false
+	}
+
+	return true
+}
+
+// parseSearchTime parses a date (2006-01-02), a timestamp (RFC 3339) or a
+// time relative to now (such as 7d, 2w, 6m or 1y ago) and reports whether
+// it was a date.
+func parseSearchTime(s string, now time.Time) (time.Time, bool, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, true
+	}
+
+	if len(s) < 2 {
+		return time.Time{}, false, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false, false
+	}
+
+	switch s[len(s)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), false, true
+	case 'w':
+		return now.AddDate(0, 0, -7*n), false, true
+	case 'm':
+		return now.AddDate(0, -n, 0), false, true
+	case 'y':
+		return now.AddDate(-n, 0, 0), false, true
+	}
+
+	return time.Time{}, false, false
+}
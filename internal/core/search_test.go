@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func TestParseSearch(t *testing.T) {
+	now := time.Date(2022, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		q    string
+		want core.SearchRequest
+	}{
+		{"", core.SearchRequest{}},
+		{"python rust", core.SearchRequest{Text: "python rust"}},
+		{`"query planner" -mysql`, core.SearchRequest{Text: `"query planner" -mysql`}},
+		{
+			"postgres blog:42 since:6m",
+			core.SearchRequest{
+				Text:    "postgres",
+				BlogIDs: []int{42},
+				Since:   time.Date(2022, 1, 15, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			`tag:"Ruby on Rails" tag:Go  sort:date`,
+			core.SearchRequest{
+				Tags: []string{"Ruby on Rails", "Go"},
+				Sort: core.SortDate,
+			},
+		},
+		{
+			"since:2022-01-01 until:2022-01-31 vacuum",
+			core.SearchRequest{
+				Text:  "vacuum",
+				Since: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		// invalid operators are just text
+		{"blog:abc sort:oldest since:soon tag: note:1", core.SearchRequest{Text: "blog:abc sort:oldest since:soon tag: note:1"}},
+	}
+
+	for _, tt := range tests {
+		got := core.ParseSearch(tt.q, now)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: want %+v, got %+v", tt.q, tt.want, got)
+		}
+	}
+}
+
+func TestSearchRequestByRelevance(t *testing.T) {
+	tests := []struct {
+		search core.SearchRequest
+		want   bool
+	}{
+		{core.SearchRequest{}, false},
+		{core.SearchRequest{Text: "go"}, true},
+		{core.SearchRequest{Text: "go", Sort: core.SortRelevance}, true},
+		{core.SearchRequest{Text: "go", Sort: core.SortDate}, false},
+		// only text searches have a rank
+		{core.SearchRequest{Tags: []string{"Go"}, Sort: core.SortRelevance}, false},
+	}
+
+	for _, tt := range tests {
+		got := tt.search.ByRelevance()
+		if got != tt.want {
+			t.Errorf("%+v: want %v, got %v", tt.search, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"time"
+)
+
+// SessionTTL is how long a login lasts.
+const SessionTTL = 30 * 24 * time.Hour
+
+// Session is a login for an account. The token that identifies a session
+// is only ever given to the client: storage only keeps a hash of it.
+type Session struct {
+	AccountID int
+	Hash      string
+	Expiry    time.Time
+
+	// readonly (from database, after creation)
+	ID int
+}
+
+// NewSession creates a session for an account along with its token.
+func NewSession(accountID int) (Session, string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	token := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	session := Session{
+		AccountID: accountID,
+		Hash:      HashSessionToken(token),
+		Expiry:    time.Now().Add(SessionTTL),
+	}
+	return session, token, nil
+}
+
+// HashSessionToken hashes a session token for lookups in storage.
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type SessionStorage interface {
+	// CreateSession also removes any of the account's expired sessions
+	CreateSession(ctx context.Context, session *Session) error
+	DeleteSession(ctx context.Context, hash string) error
+}
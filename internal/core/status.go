@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// SyncStatus tracks the health of a blog's feed across sync attempts.
+type SyncStatus struct {
+	// fields known upfront
+	BlogID       int       `json:"blog_id"`
+	LastSyncAt   time.Time `json:"last_sync_at"`
+	LastError    string    `json:"last_error"`
+	HTTPStatus   int       `json:"http_status"`
+	NewPosts     int       `json:"new_posts"`
+	UpdatedPosts int       `json:"updated_posts"`
+
+	// readonly (from database, after recording)
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	FailureCount  int        `json:"failure_count"`
+}
+
+func NewSyncStatus(blogID int, lastSyncAt time.Time) SyncStatus {
+	status := SyncStatus{
+		BlogID:     blogID,
+		LastSyncAt: lastSyncAt,
+	}
+	return status
+}
+
+// Failed reports whether the most recent sync attempt was unsuccessful.
+func (s SyncStatus) Failed() bool {
+	return s.LastError != ""
+}
+
+type SyncStatusStorage interface {
+	RecordSyncStatus(ctx context.Context, status *SyncStatus) error
+	ReadSyncStatus(ctx context.Context, blogID int) (SyncStatus, error)
+}
@@ -1,6 +1,14 @@
 package core
 
 type Storage interface {
+	AccountStorage
 	BlogStorage
+	DigestStorage
 	PostStorage
+	PostMarkStorage
+	SessionStorage
+	SubscriptionStorage
+	SyncStatusStorage
+	TagStorage
+	WebhookStorage
 }
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"context"
+)
+
+// Subscription is a blog that an account follows.
+type Subscription struct {
+	Blog
+
+	// posts that the account hasn't read yet
+	Unread int `json:"unread"`
+}
+
+// An account's subscriptions are the blogs whose posts make up its own
+// feed (see PostStorage.ReadSubscribedPostsAfter).
+type SubscriptionStorage interface {
+	// CreateSubscription returns ErrExist if the account is already
+	// subscribed and ErrNotExist if the blog doesn't exist
+	CreateSubscription(ctx context.Context, accountID, blogID int) error
+	ReadSubscriptions(ctx context.Context, accountID int, limit, offset int) ([]Subscription, error)
+	IsSubscribed(ctx context.Context, accountID, blogID int) (bool, error)
+	DeleteSubscription(ctx context.Context, accountID, blogID int) error
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+)
+
+// Tag is a topic that posts are labeled with when their content mentions
+// its name or any of its aliases.
+type Tag struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+
+	// readonly (from database, after creation)
+	ID        int `json:"id"`
+	PostCount int `json:"post_count"`
+}
+
+func NewTag(name string, aliases ...string) Tag {
+	tag := Tag{
+		Name:    name,
+		Aliases: aliases,
+	}
+	return tag
+}
+
+// Writing a tag or alias updates the tags assigned to every post that
+// matches it. Names are unique across tags and aliases (ignoring case).
+type TagStorage interface {
+	CreateTag(ctx context.Context, tag *Tag) error
+	ReadTag(ctx context.Context, id int) (Tag, error)
+	// ReadTagByName finds a tag by its name or any of its aliases
+	ReadTagByName(ctx context.Context, name string) (Tag, error)
+	ReadTags(ctx context.Context, limit, offset int) ([]Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+
+	CreateTagAlias(ctx context.Context, tagID int, alias string) error
+	DeleteTagAlias(ctx context.Context, tagID int, alias string) error
+
+	// RetagPosts recomputes the tags assigned to every post (needed only
+	// after tags are changed outside of this interface)
+	RetagPosts(ctx context.Context) error
+}
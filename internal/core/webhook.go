@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// states of a webhook delivery (pending ones are retried until they
+// either succeed or run out of attempts)
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint that new posts are sent to. Each request is
+// signed with the webhook's secret so that the receiver can verify it.
+type Webhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"-"`
+
+	// only posts with all of these tags and matching the query (in search
+	// box syntax) are sent
+	Tags  []string `json:"tags"`
+	Query string   `json:"query"`
+
+	// readonly (from database, after creation)
+	ID int `json:"id"`
+}
+
+func NewWebhook(url, secret string, tags []string, query string) Webhook {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	webhook := Webhook{
+		URL:    url,
+		Secret: secret,
+		Tags:   tags,
+		Query:  query,
+	}
+	return webhook
+}
+
+// NewWebhookSecret generates a random secret for signing requests.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Search returns the filter that posts must match to be sent. Only new
+// posts are ever sent so date filters are ignored.
+func (w Webhook) Search() SearchRequest {
+	search := ParseSearch(w.Query, time.Now())
+	search.Tags = append(search.Tags, w.Tags...)
+	search.Since = time.Time{}
+	search.Until = time.Time{}
+	return search
+}
+
+// Sign returns the signature of a request body: "sha256=" followed by the
+// hex encoded HMAC-SHA256 of the body keyed by the webhook's secret.
+func (w Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDelivery is a post sent (or waiting to be sent) to a webhook.
+type WebhookDelivery struct {
+	// fields known upfront
+	WebhookID int             `json:"webhook_id"`
+	PostID    int             `json:"post_id"`
+	Payload   json.RawMessage `json:"payload"`
+
+	// updated after each attempt
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	HTTPStatus    int        `json:"http_status"`
+	LastError     string     `json:"last_error"`
+
+	// readonly (from database, after creation)
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewWebhookDelivery(webhookID, postID int, payload []byte) WebhookDelivery {
+	delivery := WebhookDelivery{
+		WebhookID:     webhookID,
+		PostID:        postID,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	return delivery
+}
+
+type WebhookStorage interface {
+	CreateWebhook(ctx context.Context, webhook *Webhook) error
+	ReadWebhook(ctx context.Context, id int) (Webhook, error)
+	ReadWebhooks(ctx context.Context, limit, offset int) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+
+	// CreateWebhookDelivery returns ErrExist if the post was already queued
+	// for the webhook and ErrNotExist if either doesn't exist
+	CreateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	// ReadWebhookDeliveries reads a webhook's deliveries (newest first)
+	ReadWebhookDeliveries(ctx context.Context, webhookID int, limit, offset int) ([]WebhookDelivery, error)
+	// ReadDueWebhookDeliveries reads the pending deliveries whose next
+	// attempt is due by now (oldest first)
+	ReadDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery WebhookDelivery) error
+}
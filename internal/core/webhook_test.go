@@ -0,0 +1,56 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func TestWebhookSign(t *testing.T) {
+	webhook := core.NewWebhook("https://example.com/hook", "key", nil, "")
+
+	// well-known HMAC-SHA256 test vector
+	got := webhook.Sign([]byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestWebhookSearch(t *testing.T) {
+	webhook := core.NewWebhook("https://example.com/hook", "key", []string{"SQL"}, "postgres tag:Go since:6m")
+
+	search := webhook.Search()
+	if search.Text != "postgres" {
+		t.Fatalf("want %v, got %v", "postgres", search.Text)
+	}
+	if strings.Join(search.Tags, ",") != "Go,SQL" {
+		t.Fatalf("want %v, got %v", []string{"Go", "SQL"}, search.Tags)
+	}
+
+	// only new posts are sent so dates don't matter
+	if !search.Since.IsZero() {
+		t.Fatalf("want zero time, got %v", search.Since)
+	}
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	secret, err := core.NewWebhookSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret) != 64 {
+		t.Fatalf("want %v, got %v", 64, len(secret))
+	}
+
+	other, err := core.NewWebhookSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret == other {
+		t.Fatal("secrets should be random")
+	}
+}
@@ -0,0 +1,229 @@
+package feed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+
+	"github.com/theandrew168/bloggulus/internal/canonical"
+)
+
+// returned when neither a page nor its site advertise any feeds
+var ErrNoFeeds = errors.New("feed: no feeds found")
+
+// paths commonly used by blogging platforms and static site generators
+var commonPaths = []string{
+	"/feed",
+	"/feed.xml",
+	"/rss",
+	"/rss.xml",
+	"/atom.xml",
+	"/index.xml",
+	"/feed.json",
+}
+
+// MIME types advertised via <link rel="alternate">
+var feedTypes = map[string]string{
+	"application/rss+xml":   "rss",
+	"application/atom+xml":  "atom",
+	"application/feed+json": "json",
+}
+
+// Candidate is a feed found while looking for a site's feeds
+type Candidate struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+func (r *reader) DiscoverFeeds(pageURL string) ([]Candidate, error) {
+	page, err := r.fetch(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// the URL might already be a feed
+	feed, err := parseFeed(page)
+	if err == nil {
+		candidate := Candidate{
+			URL:   pageURL,
+			Title: feed.Title,
+			Type:  feed.FeedType,
+		}
+		return []Candidate{candidate}, nil
+	}
+
+	candidates := r.discover(pageURL, page)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%v: %w", pageURL, ErrNoFeeds)
+	}
+
+	return candidates, nil
+}
+
+// discover finds the feeds advertised by an HTML page. If there aren't any,
+// common feed paths below the page and then at the root of its site are
+// tried until one is found.
+func (r *reader) discover(pageURL string, page []byte) []Candidate {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	candidates := findFeedLinks(base, bytes.NewReader(page))
+	if len(candidates) > 0 {
+		return candidates
+	}
+
+	for _, feedURL := range commonFeedURLs(base) {
+		body, err := r.fetch(feedURL)
+		if err != nil {
+			continue
+		}
+
+		feed, err := parseFeed(body)
+		if err != nil {
+			continue
+		}
+
+		candidate := Candidate{
+			URL:   feedURL,
+			Title: feed.Title,
+			Type:  feed.FeedType,
+		}
+		return []Candidate{candidate}
+	}
+
+	return nil
+}
+
+// commonFeedURLs resolves the common feed paths against a page (for blogs
+// that live below their site's root, like example.com/blog/feed) and then
+// against the root of its site.
+func commonFeedURLs(base *url.URL) []string {
+	dir := *base
+	dir.Path = strings.TrimSuffix(dir.Path, "/") + "/"
+	dir.RawPath = ""
+
+	var feedURLs []string
+	seen := make(map[string]bool)
+	add := func(ref *url.URL) {
+		feedURL := ref.String()
+		if seen[feedURL] {
+			return
+		}
+		seen[feedURL] = true
+		feedURLs = append(feedURLs, feedURL)
+	}
+
+	for _, path := range commonPaths {
+		add(dir.ResolveReference(&url.URL{Path: strings.TrimPrefix(path, "/")}))
+	}
+	for _, path := range commonPaths {
+		add(base.ResolveReference(&url.URL{Path: path}))
+	}
+
+	return feedURLs
+}
+
+// findFeedLinks collects each <link rel="alternate"> with a feed type
+func findFeedLinks(base *url.URL, r io.Reader) []Candidate {
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return candidates
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := z.Token()
+		if token.Data != "link" {
+			continue
+		}
+
+		var rel, typ, href, title string
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "rel":
+				rel = strings.ToLower(attr.Val)
+			case "type":
+				typ = strings.ToLower(strings.TrimSpace(attr.Val))
+			case "href":
+				href = strings.TrimSpace(attr.Val)
+			case "title":
+				title = strings.TrimSpace(attr.Val)
+			}
+		}
+
+		feedType, ok := feedTypes[typ]
+		if !ok || href == "" || !hasField(rel, "alternate") {
+			continue
+		}
+
+		link, err := base.Parse(href)
+		if err != nil {
+			continue
+		}
+
+		// pages sometimes link the same feed more than once
+		feedURL := link.String()
+		if seen[canonical.URL(feedURL)] {
+			continue
+		}
+		seen[canonical.URL(feedURL)] = true
+
+		candidate := Candidate{
+			URL:   feedURL,
+			Title: title,
+			Type:  feedType,
+		}
+		candidates = append(candidates, candidate)
+	}
+}
+
+func hasField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reader) fetch(pageURL string) ([]byte, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{URL: pageURL, StatusCode: resp.StatusCode}
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func parseFeed(body []byte) (*gofeed.Feed, error) {
+	fp := gofeed.NewParser()
+	fp.RSSTranslator = &rssTranslator{}
+	return fp.Parse(bytes.NewReader(body))
+}
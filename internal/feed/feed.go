@@ -1,55 +1,154 @@
 package feed
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/microcosm-cc/bluemonday"
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
 
+	"github.com/theandrew168/bloggulus/internal/article"
+	"github.com/theandrew168/bloggulus/internal/canonical"
 	"github.com/theandrew168/bloggulus/internal/core"
 )
 
-// I know...
 var (
-	codePattern   = regexp.MustCompile(`(?s)<code>.*?</code>`)
-	footerPattern = regexp.MustCompile(`(?s)<footer>.*?</footer>`)
-	headerPattern = regexp.MustCompile(`(?s)<header>.*?</header>`)
-	navPattern    = regexp.MustCompile(`(?s)<nav>.*?</nav>`)
-	prePattern    = regexp.MustCompile(`(?s)<pre>.*?</pre>`)
+	// feed content shorter than this is assumed to be a summary
+	minContentLength = 500
+	// trailers that feeds append to content they've cut short
+	truncatedPattern = regexp.MustCompile(`(?i)(\.\.\.|…|\[…\]|read more|continue reading)\W*$`)
 )
 
-// please PR a better way :(
-func CleanHTML(s string) string {
-	s = codePattern.ReplaceAllString(s, "")
-	s = footerPattern.ReplaceAllString(s, "")
-	s = headerPattern.ReplaceAllString(s, "")
-	s = navPattern.ReplaceAllString(s, "")
-	s = prePattern.ReplaceAllString(s, "")
+var (
+	// returned when a conditional fetch finds nothing new
+	ErrNotModified = errors.New("feed: not modified")
+
+	userAgent = "Bloggulus/1.0"
+	timeout   = 30 * time.Second
+)
+
+// StatusError is returned when a request fails with a non-2xx HTTP status
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%v: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RSS <ttl> isn't part of gofeed's universal feed so stash it in Custom
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if rss, ok := feed.(*rss.Feed); ok && rss.TTL != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom["ttl"] = rss.TTL
+	}
 
-	s = bluemonday.StrictPolicy().Sanitize(s)
-	s = html.UnescapeString(s)
-	s = strings.ToValidUTF8(s, "")
+	return result, nil
+}
 
-	return s
+// based on the RSS syndication module:
+// https://web.resource.org/rss/1.0/modules/syndication/
+var updatePeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// syncHint determines how often a feed asks to be refreshed (zero if unknown).
+func syncHint(feed *gofeed.Feed) time.Duration {
+	// RSS <ttl> is given in minutes
+	if ttl, ok := feed.Custom["ttl"]; ok {
+		minutes, err := strconv.Atoi(strings.TrimSpace(ttl))
+		if err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+
+	// sy:updatePeriod and sy:updateFrequency (updates per period)
+	sy, ok := feed.Extensions["sy"]
+	if !ok {
+		return 0
+	}
+
+	var period time.Duration
+	if exts := sy["updatePeriod"]; len(exts) > 0 {
+		name := strings.ToLower(strings.TrimSpace(exts[0].Value))
+		period = updatePeriods[name]
+	}
+	if period == 0 {
+		return 0
+	}
+
+	frequency := 1
+	if exts := sy["updateFrequency"]; len(exts) > 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(exts[0].Value))
+		if err == nil && n > 0 {
+			frequency = n
+		}
+	}
+
+	return period / time.Duration(frequency)
 }
 
 type Reader interface {
+	// ReadBlog accepts either a feed URL or the URL of a page that links
+	// to one (such as a blog's homepage).
 	ReadBlog(feedURL string) (core.Blog, error)
-	ReadBlogPosts(blog core.Blog) ([]core.Post, error)
-	ReadPostBody(post core.Post) (string, error)
+	// DiscoverFeeds lists the feeds available from a page.
+	DiscoverFeeds(pageURL string) ([]Candidate, error)
+	// ReadBlogPosts performs a conditional fetch using the blog's cache
+	// validators and updates them (along with the feed's TTL) in place.
+	// Posts whose full content is included in the feed have their body
+	// filled in already, and each post is summarized from its description
+	// (or body) when possible.
+	// ErrNotModified is returned if the feed hasn't changed since the
+	// previous read.
+	ReadBlogPosts(blog *core.Blog) ([]core.Post, error)
+	// ReadPostBody fetches a post's page and extracts its main content as
+	// both plain text and sanitized HTML (summarizing it if the feed didn't).
+	// The post's URL is also updated in place if the page declares a
+	// canonical link for itself.
+	ReadPostBody(post *core.Post) error
+}
+
+type reader struct {
+	client *http.Client
 }
 
-type reader struct{}
+// NewReader creates a feed reader that makes at most hostLimit concurrent
+// requests to any one host and waits hostDelay between starting them.
+func NewReader(hostLimit int, hostDelay time.Duration) Reader {
+	transport := newPoliteTransport(http.DefaultTransport, hostLimit, hostDelay)
 
-func NewReader() Reader {
-	r := reader{}
+	r := reader{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}
 	return &r
 }
 
@@ -60,62 +159,223 @@ func (r *reader) ReadBlog(feedURL string) (core.Blog, error) {
 		return core.Blog{}, err
 	}
 
-	// attempt to parse the feed via gofeed
-	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(feedURL)
+	page, err := r.fetch(feedURL)
 	if err != nil {
 		return core.Blog{}, err
 	}
 
-	// create a core.Blog for the feed
-	blog := core.NewBlog(feedURL, feed.Link, feed.Title)
-	return blog, nil
+	// the URL is usually the feed itself
+	feed, err := parseFeed(page)
+	if err == nil {
+		blog := core.NewBlog(feedURL, feed.Link, feed.Title)
+		return blog, nil
+	}
+
+	// else it may be a page that links to the blog's feeds
+	for _, candidate := range r.discover(feedURL, page) {
+		body, err := r.fetch(candidate.URL)
+		if err != nil {
+			continue
+		}
+
+		feed, err := parseFeed(body)
+		if err != nil {
+			continue
+		}
+
+		blog := core.NewBlog(candidate.URL, feed.Link, feed.Title)
+		return blog, nil
+	}
+
+	return core.Blog{}, fmt.Errorf("%v: %w", feedURL, ErrNoFeeds)
 }
 
-func (r *reader) ReadBlogPosts(blog core.Blog) ([]core.Post, error) {
+func (r *reader) ReadBlogPosts(blog *core.Blog) ([]core.Post, error) {
+	req, err := http.NewRequest("GET", blog.FeedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	// only download the feed if it changed since the last read
+	if blog.ETag != "" {
+		req.Header.Set("If-None-Match", blog.ETag)
+	}
+	if blog.LastModified != "" {
+		req.Header.Set("If-Modified-Since", blog.LastModified)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{URL: blog.FeedURL, StatusCode: resp.StatusCode}
+	}
+
 	// attempt to parse the feed via gofeed
 	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(blog.FeedURL)
+	fp.RSSTranslator = &rssTranslator{}
+	feed, err := fp.Parse(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%v: %v", blog.FeedURL, err)
 	}
 
+	// remember the cache validators for the next read
+	blog.ETag = resp.Header.Get("ETag")
+	blog.LastModified = resp.Header.Get("Last-Modified")
+	blog.TTL = syncHint(feed)
+
 	// create a core.Post for each entry
 	var posts []core.Post
 	for _, item := range feed.Items {
-		// try Updated then Published to obtain a timestamp
+		// try Updated then Published to obtain a timestamp (undated
+		// entries are left zero so that sync can tell them apart)
 		var updated time.Time
 		if item.UpdatedParsed != nil {
 			updated = *item.UpdatedParsed
 		} else if item.PublishedParsed != nil {
 			updated = *item.PublishedParsed
-		} else {
-			// else default to now
-			updated = time.Now()
 		}
 
-		post := core.NewPost(item.Link, item.Title, updated, blog)
+		post := core.NewPost(strings.TrimSpace(item.Link), item.Title, updated, *blog)
+
+		// prefer the entry's own identity (RSS guid / Atom id) over its link
+		guid := strings.TrimSpace(item.GUID)
+		if guid != "" {
+			post.GUID = guid
+		}
+
+		post.Published = item.PublishedParsed
+		post.Author = authorNames(item.Authors)
+		if post.Author == "" {
+			// fall back to the feed's author (common for personal blogs)
+			post.Author = authorNames(feed.Authors)
+		}
+
+		// full-content feeds save a request for each post's page
+		content, ok := feedContent(item, post.URL)
+		if ok {
+			post.Body = strings.ToValidUTF8(content.Text, "")
+			post.BodyHTML = strings.ToValidUTF8(content.HTML, "")
+			post.BodySource = core.BodySourceFeed
+		}
+
+		// prefer the author's own description over one taken from the body
+		post.Summary = feedSummary(item)
+		if post.Summary == "" {
+			post.Summary = article.Summarize(post.Body)
+		}
+
 		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
 
-func (r *reader) ReadPostBody(post core.Post) (string, error) {
-	resp, err := http.Get(post.URL)
+// feedContent returns an entry's content (or description) if it appears to
+// be the full post rather than a summary.
+func feedContent(item *gofeed.Item, link string) (article.Article, bool) {
+	raw := item.Content
+	if strings.TrimSpace(raw) == "" {
+		raw = item.Description
+	}
+	if strings.TrimSpace(raw) == "" {
+		return article.Article{}, false
+	}
+
+	content, err := article.Fragment(strings.NewReader(raw), link)
+	if err != nil {
+		return article.Article{}, false
+	}
+
+	if len(content.Text) < minContentLength || truncatedPattern.MatchString(content.Text) {
+		return article.Article{}, false
+	}
+
+	return content, true
+}
+
+// authorNames joins the names of a feed's or entry's authors
+func authorNames(authors []*gofeed.Person) string {
+	var names []string
+	for _, author := range authors {
+		if author == nil {
+			continue
+		}
+
+		name := strings.TrimSpace(author.Name)
+		if name == "" {
+			name = strings.TrimSpace(author.Email)
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// feedSummary summarizes an entry's description (if it has one)
+func feedSummary(item *gofeed.Item) string {
+	if strings.TrimSpace(item.Description) == "" {
+		return ""
+	}
+
+	description, err := article.Fragment(strings.NewReader(item.Description), item.Link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToValidUTF8(article.Summarize(description.Text), "")
+}
+
+func (r *reader) ReadPostBody(post *core.Post) error {
+	req, err := http.NewRequest("GET", post.URL, nil)
 	if err != nil {
-		return "", fmt.Errorf("%v: %v", post.URL, err)
+		return fmt.Errorf("%v: %v", post.URL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%v: %v", post.URL, err)
 	}
 	defer resp.Body.Close()
 
+	// don't index error pages as the post's content
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{URL: post.URL, StatusCode: resp.StatusCode}
+	}
+
 	buf, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("%v: %v", post.URL, err)
+		return fmt.Errorf("%v: %v", post.URL, err)
 	}
 
-	body := string(buf)
-	body = CleanHTML(body)
-	return body, nil
+	// the page itself may know a better URL for the post
+	link, ok := canonical.Link(post.URL, bytes.NewReader(buf))
+	if ok {
+		post.URL = link
+	}
+
+	content, err := article.Extract(bytes.NewReader(buf), post.URL)
+	if err != nil {
+		return fmt.Errorf("%v: %v", post.URL, err)
+	}
+
+	post.Body = strings.ToValidUTF8(content.Text, "")
+	post.BodyHTML = strings.ToValidUTF8(content.HTML, "")
+	post.BodySource = core.BodySourcePage
+	if post.Summary == "" {
+		post.Summary = article.Summarize(post.Body)
+	}
+	return nil
 }
 
 type mockReader struct {
@@ -137,10 +397,27 @@ func (r *mockReader) ReadBlog(feedURL string) (core.Blog, error) {
 	return r.blog, nil
 }
 
-func (r *mockReader) ReadBlogPosts(blog core.Blog) ([]core.Post, error) {
+func (r *mockReader) DiscoverFeeds(pageURL string) ([]Candidate, error) {
+	candidate := Candidate{
+		URL:   r.blog.FeedURL,
+		Title: r.blog.Title,
+	}
+	return []Candidate{candidate}, nil
+}
+
+func (r *mockReader) ReadBlogPosts(blog *core.Blog) ([]core.Post, error) {
+	// respond with the mock blog's cache validators
+	blog.ETag = r.blog.ETag
+	blog.LastModified = r.blog.LastModified
 	return r.posts, nil
 }
 
-func (r *mockReader) ReadPostBody(post core.Post) (string, error) {
-	return r.body, nil
+func (r *mockReader) ReadPostBody(post *core.Post) error {
+	post.Body = r.body
+	post.BodyHTML = html.EscapeString(r.body)
+	post.BodySource = core.BodySourcePage
+	if post.Summary == "" {
+		post.Summary = article.Summarize(r.body)
+	}
+	return nil
 }
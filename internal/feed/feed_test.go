@@ -1,32 +1,509 @@
 package feed_test
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/theandrew168/bloggulus/internal/core"
 	"github.com/theandrew168/bloggulus/internal/feed"
 )
 
-func TestCleanHTML(t *testing.T) {
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example Feed</title>
+	<link href="http://example.org/"/>
+	<updated>2003-12-13T18:30:02Z</updated>
+	<id>urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6</id>
+	<entry>
+		<title>Atom-Powered Robots Run Amok</title>
+		<link href="http://example.org/2003/12/13/atom03"/>
+		<id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+		<updated>2003-12-13T18:30:02Z</updated>
+	</entry>
+</feed>`
+
+func TestReadBlogPostsGUID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(atomFeed))
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+
+	posts, err := reader.ReadBlogPosts(&blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(posts))
+	}
+
+	// the Atom id should identify the post rather than its link
+	want := "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a"
+	if posts[0].GUID != want {
+		t.Fatalf("want %v, got %v", want, posts[0].GUID)
+	}
+}
+
+func TestReadBlogPostsMetadata(t *testing.T) {
+	atomFeed := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example Feed</title>
+	<link href="http://example.org/"/>
+	<updated>2003-12-13T18:30:02Z</updated>
+	<author><name>John Doe</name></author>
+	<id>urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6</id>
+	<entry>
+		<title>Edited Post</title>
+		<link href="http://example.org/2003/12/13/edited"/>
+		<id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+		<published>2003-12-13T08:29:29-04:00</published>
+		<updated>2003-12-14T18:30:02Z</updated>
+		<author><name>Jane Doe</name></author>
+		<author><name>Mark Pilgrim</name></author>
+	</entry>
+	<entry>
+		<title>Undated Post</title>
+		<link href="http://example.org/2003/12/13/undated"/>
+		<id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6b</id>
+	</entry>
+</feed>`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(atomFeed))
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+
+	posts, err := reader.ReadBlogPosts(&blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(posts))
+	}
+
+	// published and updated are kept apart
+	edited := posts[0]
+	published := time.Date(2003, 12, 13, 12, 29, 29, 0, time.UTC)
+	if edited.Published == nil || !edited.Published.Equal(published) {
+		t.Errorf("want %v, got %v", published, edited.Published)
+	}
+	updated := time.Date(2003, 12, 14, 18, 30, 2, 0, time.UTC)
+	if !edited.Updated.Equal(updated) {
+		t.Errorf("want %v, got %v", updated, edited.Updated)
+	}
+	if edited.Author != "Jane Doe, Mark Pilgrim" {
+		t.Errorf("want %v, got %v", "Jane Doe, Mark Pilgrim", edited.Author)
+	}
+
+	// undated entries are left for sync to date and inherit the feed's author
+	undated := posts[1]
+	if undated.Published != nil || !undated.Updated.IsZero() {
+		t.Errorf("want undated post, got %v / %v", undated.Published, undated.Updated)
+	}
+	if undated.Author != "John Doe" {
+		t.Errorf("want %v, got %v", "John Doe", undated.Author)
+	}
+}
+
+func TestReadBlogPostsContent(t *testing.T) {
+	paragraph := "<p>This paragraph is repeated a few times so that the entry looks like a full post instead of a short summary.</p>"
+	full := strings.Repeat(paragraph, 6)
+
 	tests := []struct {
-		input string
-		want  string
+		name       string
+		item       string
+		wantSource string
 	}{
-		{"<code>hello world</code>", ""},
-		{"<footer>hello world</footer>", ""},
-		{"<header>hello world</header>", ""},
-		{"<nav>hello world</nav>", ""},
-		{"<pre>hello world</pre>", ""},
+		{"content", "<content:encoded><![CDATA[" + full + "]]></content:encoded>", core.BodySourceFeed},
+		{"description", "<description><![CDATA[" + full + "]]></description>", core.BodySourceFeed},
+		{"summary", "<description><![CDATA[" + paragraph + "]]></description>", ""},
+		{"truncated", "<description><![CDATA[" + full + "<p>Continue reading &rarr;</p>]]></description>", ""},
+		{"ellipsis", "<description><![CDATA[" + full + "<p>And so [&hellip;]</p>]]></description>", ""},
+		{"none", "", ""},
+	}
+
+	for _, test := range tests {
+		rssFeed := fmt.Sprintf(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+	<channel>
+		<title>Example Feed</title>
+		<link>http://example.org/</link>
+		<item>
+			<title>Example Post</title>
+			<link>http://example.org/posts/example/</link>
+			%s
+		</item>
+	</channel>
+</rss>`, test.item)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(rssFeed))
+		}))
 
-		{"hello world", "hello world"},
-		{"<p>hello world</p>", "hello world"},
-		{"<code><p>hello world</p></code>", ""},
+		reader := feed.NewReader(2, 0)
+		blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+
+		posts, err := reader.ReadBlogPosts(&blog)
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(posts) != 1 {
+			t.Fatalf("%s: want %v, got %v", test.name, 1, len(posts))
+		}
 
-		{"<script>console.log('hello')</script>", ""},
+		post := posts[0]
+		if post.BodySource != test.wantSource {
+			t.Errorf("%s: want source %q, got %q", test.name, test.wantSource, post.BodySource)
+		}
+		if test.wantSource == "" && post.Body != "" {
+			t.Errorf("%s: want empty body, got %q", test.name, post.Body)
+		}
+		if test.wantSource == core.BodySourceFeed && !strings.Contains(post.BodyHTML, paragraph) {
+			t.Errorf("%s: body HTML is missing content: %q", test.name, post.BodyHTML)
+		}
+		if test.item != "" && !strings.HasPrefix(post.Summary, "This paragraph is repeated") {
+			t.Errorf("%s: want summary from feed, got %q", test.name, post.Summary)
+		}
+	}
+}
+
+func TestReadBlogPostsNotModified(t *testing.T) {
+	etag := `"abc123"`
+	lastModified := "Sat, 13 Dec 2003 18:30:02 GMT"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(atomFeed))
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+
+	// first read should return posts and record the cache validators
+	posts, err := reader.ReadBlogPosts(&blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(posts))
+	}
+	if blog.ETag != etag {
+		t.Fatalf("want %v, got %v", etag, blog.ETag)
+	}
+	if blog.LastModified != lastModified {
+		t.Fatalf("want %v, got %v", lastModified, blog.LastModified)
+	}
+
+	// second read should be skipped by the server
+	_, err = reader.ReadBlogPosts(&blog)
+	if !errors.Is(err, feed.ErrNotModified) {
+		t.Fatalf("want %v, got %v", feed.ErrNotModified, err)
+	}
+}
+
+func TestReadBlogPostsTTL(t *testing.T) {
+	tests := []struct {
+		channel string
+		want    time.Duration
+	}{
+		{"<ttl>60</ttl>", time.Hour},
+		{"<sy:updatePeriod>daily</sy:updatePeriod>", 24 * time.Hour},
+		{"<sy:updatePeriod>hourly</sy:updatePeriod><sy:updateFrequency>2</sy:updateFrequency>", 30 * time.Minute},
+		{"", 0},
 	}
 
 	for _, test := range tests {
-		if got := feed.CleanHTML(test.input); got != test.want {
-			t.Errorf("feed.CleanHTML(%q) = %v", test.input, got)
+		rssFeed := fmt.Sprintf(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+	<channel>
+		<title>Example Feed</title>
+		<link>http://example.org/</link>
+		%s
+	</channel>
+</rss>`, test.channel)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(rssFeed))
+		}))
+
+		reader := feed.NewReader(2, 0)
+		blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+
+		_, err := reader.ReadBlogPosts(&blog)
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if blog.TTL != test.want {
+			t.Errorf("%q: want %v, got %v", test.channel, test.want, blog.TTL)
+		}
+	}
+}
+
+func TestReaderHostPoliteness(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
 		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		w.Write([]byte(atomFeed))
+	}))
+	defer ts.Close()
+
+	limit := 2
+	delay := 10 * time.Millisecond
+	reader := feed.NewReader(limit, delay)
+
+	// fire off a bunch of simultaneous requests to the same host
+	n := 6
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+			_, err := reader.ReadBlogPosts(&blog)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxActive > limit {
+		t.Fatalf("want at most %v concurrent requests, got %v", limit, maxActive)
+	}
+
+	// requests must be spaced apart by at least the delay
+	if elapsed := time.Since(start); elapsed < time.Duration(n-1)*delay {
+		t.Fatalf("want at least %v elapsed, got %v", time.Duration(n-1)*delay, elapsed)
+	}
+}
+
+func TestReadPostBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body>
+			<nav><a href="/">Home</a></nav>
+			<article><p>Hello World! This is the first post on this blog, and it is long enough to be taken seriously as an article.</p></article>
+			<div class="comments"><p>First!</p></div>
+		</body></html>`)
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+	post := core.NewPost(ts.URL+"/hello", "Hello", time.Now(), blog)
+
+	err := reader.ReadPostBody(&post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantBody := "Hello World! This is the first post on this blog, and it is long enough to be taken seriously as an article."
+	if post.Body != wantBody {
+		t.Errorf("want body %q, got %q", wantBody, post.Body)
+	}
+
+	wantHTML := "<p>" + wantBody + "</p>"
+	if post.BodyHTML != wantHTML {
+		t.Errorf("want HTML %q, got %q", wantHTML, post.BodyHTML)
+	}
+}
+
+func TestReadPostBodyCanonical(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><head><link rel="canonical" href="%s/posts/hello/"></head><body>hello</body></html>`, ts.URL)
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+	post := core.NewPost(ts.URL+"/amp/hello?utm_source=rss", "Hello", time.Now(), blog)
+
+	err := reader.ReadPostBody(&post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ts.URL + "/posts/hello/"
+	if post.URL != want {
+		t.Fatalf("want %v, got %v", want, post.URL)
+	}
+}
+
+func TestReadPostBodyStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "this page is long gone but its error message is still long enough to look like an article", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	blog := core.NewBlog(ts.URL, ts.URL, "Example Feed")
+	post := core.NewPost(ts.URL+"/hello", "Hello", time.Now(), blog)
+
+	err := reader.ReadPostBody(&post)
+
+	var statusErr *feed.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("want %T, got %v", statusErr, err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("want %v, got %v", http.StatusNotFound, statusErr.StatusCode)
+	}
+	if post.Body != "" {
+		t.Fatalf("want no body, got %q", post.Body)
+	}
+}
+
+const homepage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Example Blog</title>
+	<link rel="stylesheet" href="/style.css">
+	<link rel="alternate" type="application/atom+xml" title="Atom" href="/feed.atom">
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="https://EXAMPLE.org/feed.rss?utm_source=site">
+	<link rel="alternate" type="application/rss+xml" title="RSS (again)" href="https://example.org/feed.rss">
+	<link rel="alternate" type="text/html" hreflang="fr" href="/fr/">
+</head>
+<body>hello</body>
+</html>`
+
+func TestDiscoverFeeds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(homepage))
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	candidates, err := reader.DiscoverFeeds(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []feed.Candidate{
+		{URL: ts.URL + "/feed.atom", Title: "Atom", Type: "atom"},
+		{URL: "https://EXAMPLE.org/feed.rss?utm_source=site", Title: "RSS", Type: "rss"},
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("want %v, got %v", want, candidates)
+	}
+	for i := range want {
+		if candidates[i] != want[i] {
+			t.Fatalf("want %v, got %v", want[i], candidates[i])
+		}
+	}
+}
+
+func TestDiscoverFeedsCommonPathsBelowRoot(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blog", "/blog/":
+			w.Write([]byte("<html><head></head><body>no links here</body></html>"))
+		case "/blog/feed":
+			w.Write([]byte(atomFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+
+	// blogs below the root of a site keep their feeds there too
+	candidates, err := reader.DiscoverFeeds(ts.URL + "/blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ts.URL + "/blog/feed"
+	if len(candidates) != 1 || candidates[0].URL != want {
+		t.Fatalf("want %v, got %v", want, candidates)
+	}
+}
+
+func TestDiscoverFeedsCommonPaths(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte("<html><head></head><body>no links here</body></html>"))
+		case "/index.xml":
+			w.Write([]byte(atomFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+
+	// homepages without feed links should fall back to common paths
+	blog, err := reader.ReadBlog(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ts.URL + "/index.xml"
+	if blog.FeedURL != want {
+		t.Fatalf("want %v, got %v", want, blog.FeedURL)
+	}
+	if blog.Title != "Example Feed" {
+		t.Fatalf("want %v, got %v", "Example Feed", blog.Title)
+	}
+}
+
+func TestDiscoverFeedsNone(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><head></head><body>no feeds here</body></html>"))
+	}))
+	defer ts.Close()
+
+	reader := feed.NewReader(2, 0)
+	_, err := reader.DiscoverFeeds(ts.URL)
+	if !errors.Is(err, feed.ErrNoFeeds) {
+		t.Fatalf("want %v, got %v", feed.ErrNoFeeds, err)
 	}
 }
@@ -0,0 +1,140 @@
+package feed
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// politeTransport limits the number of concurrent requests made to each
+// host and enforces a minimum delay between the start of those requests.
+type politeTransport struct {
+	transport http.RoundTripper
+	limit     int
+	delay     time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newPoliteTransport(transport http.RoundTripper, limit int, delay time.Duration) *politeTransport {
+	if limit < 1 {
+		limit = 1
+	}
+
+	t := politeTransport{
+		transport: transport,
+		limit:     limit,
+		delay:     delay,
+		hosts:     make(map[string]*hostState),
+	}
+	return &t
+}
+
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := t.host(req.URL.Hostname())
+
+	// wait for a free slot on this host
+	select {
+	case host.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// reserve the next start time for this host
+	host.mu.Lock()
+	start := time.Now()
+	if host.next.After(start) {
+		start = host.next
+	}
+	host.next = start.Add(t.delay)
+	host.mu.Unlock()
+
+	// wait until the reserved start time
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		<-host.slots
+		return nil, ctx.Err()
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		<-host.slots
+		return nil, err
+	}
+
+	// hold the slot until the body has been read and closed
+	resp.Body = &releaseBody{
+		ReadCloser: resp.Body,
+		release:    func() { <-host.slots },
+	}
+	return resp, nil
+}
+
+func (t *politeTransport) host(name string) *hostState {
+	key := hostKey(name)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	host, ok := t.hosts[key]
+	if !ok {
+		host = &hostState{
+			slots: make(chan struct{}, t.limit),
+		}
+		t.hosts[key] = host
+	}
+
+	return host
+}
+
+// hostKey groups hosts by registered domain so that blogs living on shared
+// platforms (alice.github.io, bob.substack.com) count as the same host.
+func hostKey(name string) string {
+	name = strings.ToLower(name)
+	if net.ParseIP(name) != nil {
+		return name
+	}
+
+	// private suffixes (github.io) identify the shared platform itself
+	suffix, icann := publicsuffix.PublicSuffix(name)
+	if !icann && strings.Contains(suffix, ".") {
+		return suffix
+	}
+
+	key, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return name
+	}
+
+	return key
+}
+
+type releaseBody struct {
+	io.ReadCloser
+
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,262 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+// Channel is a feed of posts published by bloggulus itself
+type Channel struct {
+	Title   string
+	Link    string
+	FeedURL string
+	Updated time.Time
+	Posts   []core.Post
+}
+
+// based on RFC 4287:
+// https://datatracker.ietf.org/doc/html/rfc4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Link       atomLink       `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func WriteAtom(w io.Writer, ch Channel) error {
+	feed := atomFeed{
+		Title:   ch.Title,
+		ID:      ch.FeedURL,
+		Updated: ch.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: ch.Link, Rel: "alternate", Type: "text/html"},
+			{Href: ch.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, post := range ch.Posts {
+		entry := atomEntry{
+			Title:   post.Title,
+			ID:      post.URL,
+			Updated: post.Updated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: post.URL, Rel: "alternate"},
+			Summary: post.Summary,
+			Author: atomAuthor{
+				Name: authorName(post),
+				URI:  post.Blog.SiteURL,
+			},
+		}
+		if post.Published != nil {
+			entry.Published = post.Published.UTC().Format(time.RFC3339)
+		}
+		for _, tag := range post.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return writeXML(w, feed)
+}
+
+// authorName credits a post's author (if known) or else its blog
+func authorName(post core.Post) string {
+	if post.Author != "" {
+		return post.Author
+	}
+	return post.Blog.Title
+}
+
+// based on the RSS 2.0 spec:
+// https://www.rssboard.org/rss-specification
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description,omitempty"`
+	GUID        rssGUID   `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+	Source      rssSource `xml:"source"`
+	Categories  []string  `xml:"category"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssSource struct {
+	Title string `xml:",chardata"`
+	URL   string `xml:"url,attr"`
+}
+
+func WriteRSS(w io.Writer, ch Channel) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         ch.Title,
+			Link:          ch.Link,
+			Description:   ch.Title,
+			LastBuildDate: ch.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink: atomLink{
+				Href: ch.FeedURL,
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+		},
+	}
+
+	for _, post := range ch.Posts {
+		item := rssItem{
+			Title:       post.Title,
+			Link:        post.URL,
+			Description: post.Summary,
+			GUID:        rssGUID{Value: post.URL, IsPermaLink: true},
+			PubDate:     post.Updated.UTC().Format(time.RFC1123Z),
+			Source: rssSource{
+				Title: post.Blog.Title,
+				URL:   post.Blog.FeedURL,
+			},
+			Categories: post.Tags,
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+
+	err = enc.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	// append a newline for nicer terminal output
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// based on the JSON Feed 1.1 spec:
+// https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentText   string       `json:"content_text"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published,omitempty"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+func WriteJSON(w io.Writer, ch Channel) error {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       ch.Title,
+		HomePageURL: ch.Link,
+		FeedURL:     ch.FeedURL,
+		Items:       make([]jsonItem, 0),
+	}
+
+	for _, post := range ch.Posts {
+		// content_text is required so fall back to the title
+		content := post.Summary
+		if content == "" {
+			content = post.Title
+		}
+
+		item := jsonItem{
+			ID:           post.URL,
+			URL:          post.URL,
+			Title:        post.Title,
+			ContentText:  content,
+			Summary:      post.Summary,
+			DateModified: post.Updated.UTC().Format(time.RFC3339),
+			Authors: []jsonAuthor{
+				{Name: authorName(post), URL: post.Blog.SiteURL},
+			},
+			Tags: post.Tags,
+		}
+
+		if post.Published != nil {
+			item.DatePublished = post.Published.UTC().Format(time.RFC3339)
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(feed)
+}
@@ -0,0 +1,93 @@
+package feed_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+)
+
+func TestWrite(t *testing.T) {
+	blog := core.NewBlog("https://example.org/feed.xml", "https://example.org", "Example Blog")
+	updated := time.Date(2022, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	post := core.NewPost("https://example.org/post", "Example <Post> & Friends", updated, blog)
+	post.Summary = "An example post about <things> & stuff."
+	post.Author = "Jane Doe"
+	post.Tags = []string{"Golang", "Rust"}
+
+	ch := feed.Channel{
+		Title:   "Bloggulus",
+		Link:    "https://bloggulus.com/",
+		FeedURL: "https://bloggulus.com/feed",
+		Updated: updated,
+		Posts:   []core.Post{post},
+	}
+
+	tests := []struct {
+		name  string
+		write func(io.Writer, feed.Channel) error
+		want  string
+	}{
+		{"atom", feed.WriteAtom, "atom"},
+		{"rss", feed.WriteRSS, "rss"},
+		{"json", feed.WriteJSON, "json"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := test.write(&buf, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// parse the output to ensure it's a valid feed
+		fp := gofeed.NewParser()
+		got, err := fp.Parse(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if got.FeedType != test.want {
+			t.Errorf("%s: want %v, got %v", test.name, test.want, got.FeedType)
+		}
+		if got.Title != ch.Title {
+			t.Errorf("%s: want %v, got %v", test.name, ch.Title, got.Title)
+		}
+		if len(got.Items) != 1 {
+			t.Fatalf("%s: want %v, got %v", test.name, 1, len(got.Items))
+		}
+
+		item := got.Items[0]
+		if item.Title != post.Title {
+			t.Errorf("%s: want %v, got %v", test.name, post.Title, item.Title)
+		}
+		if item.Link != post.URL {
+			t.Errorf("%s: want %v, got %v", test.name, post.URL, item.Link)
+		}
+		if item.Description != post.Summary {
+			t.Errorf("%s: want %v, got %v", test.name, post.Summary, item.Description)
+		}
+		if test.name != "rss" && (len(item.Authors) != 1 || item.Authors[0].Name != post.Author) {
+			t.Errorf("%s: want %v, got %v", test.name, post.Author, item.Authors)
+		}
+		if len(item.Categories) != len(post.Tags) {
+			t.Errorf("%s: want %v, got %v", test.name, post.Tags, item.Categories)
+		}
+
+		var date *time.Time
+		if item.UpdatedParsed != nil {
+			date = item.UpdatedParsed
+		} else {
+			date = item.PublishedParsed
+		}
+		if date == nil || !date.Equal(updated) {
+			t.Errorf("%s: want %v, got %v", test.name, updated, date)
+		}
+	}
+}
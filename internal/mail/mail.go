@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// returned when an address or subject would break the message's headers
+var ErrInvalidHeader = errors.New("mail: invalid header")
+
+// Sender delivers HTML emails.
+type Sender interface {
+	Send(to []string, subject, body string) error
+}
+
+type smtpSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSender creates a sender that relays mail through an SMTP server. The
+// connection is upgraded with STARTTLS when the server supports it and
+// credentials are only sent if a username is given.
+func NewSender(host, port, username, password, from string) Sender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	s := smtpSender{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+		auth: auth,
+	}
+	return &s
+}
+
+func (s *smtpSender) Send(to []string, subject, body string) error {
+	msg, err := newMessage(s.from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(s.addr, s.auth, s.from, to, msg)
+}
+
+// newMessage formats an HTML email (quoted-printable so that long lines
+// stay within SMTP's limits).
+func newMessage(from string, to []string, subject, body string, date time.Time) ([]byte, error) {
+	headers := append([]string{from, subject}, to...)
+	for _, header := range headers {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: text/html; charset=UTF-8\r\n")
+	fmt.Fprintf(&b, "Content-Transfer-Encoding: quoted-printable\r\n")
+	fmt.Fprintf(&b, "\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	_, err := w.Write([]byte(body))
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package mail_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/mail"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestSend(t *testing.T) {
+	server := test.NewSMTPServer(t)
+	sender := mail.NewSender(server.Host, server.Port, "", "", "bloggulus@example.com")
+
+	to := []string{"alice@example.com", "bob@example.com"}
+	subject := "Bloggulus — 2 new posts"
+	body := "<p>" + strings.Repeat("a=b ", 100) + "</p>"
+
+	err := sender.Send(to, subject, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(messages))
+	}
+
+	msg := messages[0]
+	if msg.From != "bloggulus@example.com" {
+		t.Fatalf("want %v, got %v", "bloggulus@example.com", msg.From)
+	}
+	if strings.Join(msg.To, ",") != strings.Join(to, ",") {
+		t.Fatalf("want %v, got %v", to, msg.To)
+	}
+	if msg.Subject != subject {
+		t.Fatalf("want %v, got %v", subject, msg.Subject)
+	}
+	// SMTP ends the message with a line break
+	if strings.TrimSpace(msg.Body) != body {
+		t.Fatalf("want %v, got %v", body, msg.Body)
+	}
+}
+
+func TestSendInvalidHeader(t *testing.T) {
+	server := test.NewSMTPServer(t)
+	sender := mail.NewSender(server.Host, server.Port, "", "", "bloggulus@example.com")
+
+	err := sender.Send([]string{"alice@example.com"}, "Hello\r\nBcc: eve@example.com", "<p>Hi</p>")
+	if !errors.Is(err, mail.ErrInvalidHeader) {
+		t.Fatalf("want %v, got %v", mail.ErrInvalidHeader, err)
+	}
+
+	if len(server.Messages()) != 0 {
+		t.Fatal("nothing should be sent with an invalid header")
+	}
+}
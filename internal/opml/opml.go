@@ -0,0 +1,202 @@
+package opml
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+)
+
+// based on the OPML 2.0 spec:
+// http://opml.org/spec2.opml
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Category string    `xml:"category,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a single subscription found within an OPML document
+type Feed struct {
+	Category string
+	Title    string
+	URL      string
+}
+
+func Parse(r io.Reader) (OPML, error) {
+	var doc OPML
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return OPML{}, err
+	}
+
+	return doc, nil
+}
+
+// Feeds flattens the document's outlines into a list of feeds. Outlines
+// nested under folders are categorized by the folder's name, otherwise
+// the outline's own category attribute is used.
+func (doc OPML) Feeds() []Feed {
+	var feeds []Feed
+	for _, outline := range doc.Body.Outlines {
+		feeds = append(feeds, outline.feeds("")...)
+	}
+	return feeds
+}
+
+func (o Outline) feeds(folder string) []Feed {
+	if o.XMLURL != "" {
+		category := folder
+		if category == "" {
+			category = firstCategory(o.Category)
+		}
+
+		title := o.Title
+		if title == "" {
+			title = o.Text
+		}
+
+		feed := Feed{
+			Category: category,
+			Title:    title,
+			URL:      strings.TrimSpace(o.XMLURL),
+		}
+		return []Feed{feed}
+	}
+
+	// outlines without a feed URL are folders
+	name := o.Text
+	if name == "" {
+		name = o.Title
+	}
+	if folder != "" {
+		name = folder + "/" + name
+	}
+
+	var feeds []Feed
+	for _, child := range o.Outlines {
+		feeds = append(feeds, child.feeds(name)...)
+	}
+	return feeds
+}
+
+// category attrs are comma-separated slash-delimited paths: "/Tech/Go,/News"
+func firstCategory(attr string) string {
+	category := strings.Split(attr, ",")[0]
+	return strings.Trim(strings.TrimSpace(category), "/")
+}
+
+// New creates an OPML document listing the given blogs.
+func New(title string, blogs []core.Blog) OPML {
+	doc := OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, blog := range blogs {
+		outline := Outline{
+			Text:    blog.Title,
+			Title:   blog.Title,
+			Type:    "rss",
+			XMLURL:  blog.FeedURL,
+			HTMLURL: blog.SiteURL,
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, outline)
+	}
+
+	return doc
+}
+
+func Write(w io.Writer, doc OPML) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	// append a newline for nicer terminal output
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+type ImportStatus string
+
+const (
+	ImportAdded  ImportStatus = "added"
+	ImportExists ImportStatus = "exists"
+	ImportFailed ImportStatus = "failed"
+)
+
+type ImportResult struct {
+	Feed   Feed
+	Status ImportStatus
+	Err    error
+}
+
+// Import adds each feed as a blog. Every feed is attempted and the outcome
+// of each is returned in the same order as the input.
+func Import(ctx context.Context, storage core.BlogStorage, reader feed.Reader, feeds []Feed) []ImportResult {
+	var results []ImportResult
+	for _, f := range feeds {
+		result := ImportResult{
+			Feed: f,
+		}
+
+		blog, err := reader.ReadBlog(f.URL)
+		if err != nil {
+			result.Status = ImportFailed
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		err = storage.CreateBlog(ctx, &blog)
+		switch {
+		case err == nil:
+			result.Status = ImportAdded
+		case errors.Is(err, core.ErrExist):
+			result.Status = ImportExists
+		default:
+			result.Status = ImportFailed
+			result.Err = err
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
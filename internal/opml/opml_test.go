@@ -0,0 +1,117 @@
+package opml_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/opml"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+const document = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+	<head>
+		<title>Subscriptions</title>
+	</head>
+	<body>
+		<outline text="Tech">
+			<outline text="Go" title="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+			<outline text="Languages">
+				<outline text="Rust" type="rss" xmlUrl="https://blog.rust-lang.org/feed.xml"/>
+			</outline>
+		</outline>
+		<outline text="Julia" type="rss" xmlUrl="https://julialang.org/feed.xml" category="/Science/Computing,/Other"/>
+		<outline text="Misc" type="rss" xmlUrl="https://example.org/feed.xml"/>
+	</body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	doc, err := opml.Parse(strings.NewReader(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []opml.Feed{
+		{"Tech", "The Go Blog", "https://go.dev/blog/feed.atom"},
+		{"Tech/Languages", "Rust", "https://blog.rust-lang.org/feed.xml"},
+		{"Science/Computing", "Julia", "https://julialang.org/feed.xml"},
+		{"", "Misc", "https://example.org/feed.xml"},
+	}
+
+	got := doc.Feeds()
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", len(want), len(got))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %v, got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	blogs := []core.Blog{
+		core.NewBlog("https://go.dev/blog/feed.atom", "https://go.dev/blog", "The Go Blog"),
+		core.NewBlog("https://blog.rust-lang.org/feed.xml", "https://blog.rust-lang.org", "Rust Blog"),
+	}
+
+	var buf bytes.Buffer
+	err := opml.Write(&buf, opml.New("Bloggulus", blogs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// parse the output to ensure it round trips
+	doc, err := opml.Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feeds := doc.Feeds()
+	if len(feeds) != len(blogs) {
+		t.Fatalf("want %v, got %v", len(blogs), len(feeds))
+	}
+
+	for i, blog := range blogs {
+		if feeds[i].URL != blog.FeedURL {
+			t.Errorf("want %v, got %v", blog.FeedURL, feeds[i].URL)
+		}
+		if feeds[i].Title != blog.Title {
+			t.Errorf("want %v, got %v", blog.Title, feeds[i].Title)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+
+	// the mock reader returns the same blog for every feed
+	blog := test.NewMockBlog()
+	reader := feed.NewMockReader(blog, nil, "")
+
+	feeds := []opml.Feed{
+		{"Tech", blog.Title, blog.FeedURL},
+		{"Tech", blog.Title, blog.FeedURL},
+	}
+
+	results := opml.Import(context.Background(), storage, reader, feeds)
+	if len(results) != len(feeds) {
+		t.Fatalf("want %v, got %v", len(feeds), len(results))
+	}
+
+	if results[0].Status != opml.ImportAdded {
+		t.Fatalf("want %v, got %v", opml.ImportAdded, results[0].Status)
+	}
+	if results[1].Status != opml.ImportExists {
+		t.Fatalf("want %v, got %v", opml.ImportExists, results[1].Status)
+	}
+}
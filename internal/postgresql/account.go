@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateAccount(ctx context.Context, account *core.Account) error {
+	stmt := `
+		INSERT INTO account
+			(username, password_hash)
+		VALUES
+			($1, $2)
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, account.Username, account.PasswordHash)
+
+	err := scan(row, &account.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateAccount(ctx, account)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadAccount(ctx context.Context, id int) (core.Account, error) {
+	stmt := `
+		SELECT
+			id,
+			username,
+			password_hash
+		FROM account
+		WHERE id = $1`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var account core.Account
+	err := scan(
+		row,
+		&account.ID,
+		&account.Username,
+		&account.PasswordHash,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadAccount(ctx, id)
+		}
+		return core.Account{}, err
+	}
+
+	return account, nil
+}
+
+func (s *storage) ReadAccountByUsername(ctx context.Context, username string) (core.Account, error) {
+	stmt := `
+		SELECT
+			id,
+			username,
+			password_hash
+		FROM account
+		WHERE lower(username) = lower($1)`
+	row := s.conn.QueryRow(ctx, stmt, username)
+
+	var account core.Account
+	err := scan(
+		row,
+		&account.ID,
+		&account.Username,
+		&account.PasswordHash,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadAccountByUsername(ctx, username)
+		}
+		return core.Account{}, err
+	}
+
+	return account, nil
+}
+
+func (s *storage) ReadAccountBySession(ctx context.Context, hash string) (core.Account, error) {
+	stmt := `
+		SELECT
+			account.id,
+			account.username,
+			account.password_hash
+		FROM session
+		INNER JOIN account
+			ON account.id = session.account_id
+		WHERE session.hash = $1
+			AND session.expiry > NOW()`
+	row := s.conn.QueryRow(ctx, stmt, hash)
+
+	var account core.Account
+	err := scan(
+		row,
+		&account.ID,
+		&account.Username,
+		&account.PasswordHash,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadAccountBySession(ctx, hash)
+		}
+		return core.Account{}, err
+	}
+
+	return account, nil
+}
@@ -0,0 +1,64 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestCreateAccount(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateAccount(storage, t)
+}
+
+func TestCreateAccountAlreadyExists(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateAccountAlreadyExists(storage, t)
+}
+
+func TestReadAccount(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadAccount(storage, t)
+}
+
+func TestReadAccountByUsername(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadAccountByUsername(storage, t)
+}
+
+func TestReadAccountBySession(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadAccountBySession(storage, t)
+}
+
+func TestReadAccountBySessionExpired(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadAccountBySessionExpired(storage, t)
+}
+
+func TestDeleteSession(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.DeleteSession(storage, t)
+}
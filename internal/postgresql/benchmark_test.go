@@ -0,0 +1,178 @@
+package postgresql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+// Tags used to be matched against every post's content (and ranked) on
+// every read. These are the original queries, kept around to benchmark the
+// precomputed post_tag lookups against.
+const (
+	readPostTagJoin = `
+		SELECT
+			post.id,
+			post.url,
+			post.title,
+			post.updated,
+			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(post.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			blog.id,
+			blog.feed_url,
+			blog.site_url,
+			blog.title
+		FROM post
+		INNER JOIN blog
+			ON blog.id = post.blog_id
+		LEFT JOIN tag
+			ON to_tsquery(tag.name) @@ post.content_index
+		WHERE post.id = $1
+		GROUP BY 1,2,3,4,6,7,8,9`
+
+	readPostsTagJoin = `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.title,
+				post.updated,
+				post.content_index,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			ORDER BY post.updated DESC
+			LIMIT $1 OFFSET $2
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.title,
+			posts.updated,
+			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(posts.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN tag
+			ON to_tsquery(tag.name) @@ posts.content_index
+		GROUP BY 1,2,3,4,6,7,8,9
+		ORDER BY posts.updated DESC`
+)
+
+const benchmarkPageSize = 15
+
+func BenchmarkReadPost(b *testing.B) {
+	conn := test.ConnectDB(b)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	post := createBenchmarkPosts(storage, b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := storage.ReadPost(context.Background(), post.ID)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadPostTagJoin(b *testing.B) {
+	conn := test.ConnectDB(b)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	post := createBenchmarkPosts(storage, b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		queryBenchmarkPosts(conn, b, readPostTagJoin, post.ID)
+	}
+}
+
+func BenchmarkReadPosts(b *testing.B) {
+	conn := test.ConnectDB(b)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	createBenchmarkPosts(storage, b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := storage.ReadPosts(context.Background(), benchmarkPageSize, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadPostsTagJoin(b *testing.B) {
+	conn := test.ConnectDB(b)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	createBenchmarkPosts(storage, b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		queryBenchmarkPosts(conn, b, readPostsTagJoin, benchmarkPageSize, 0)
+	}
+}
+
+// createBenchmarkPosts adds a page of recent posts that mention a few of
+// the default tags and returns the last one.
+func createBenchmarkPosts(storage core.Storage, b *testing.B) core.Post {
+	b.Helper()
+
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var post core.Post
+	for i := 0; i < benchmarkPageSize; i++ {
+		post = test.NewMockPost(blog)
+		post.Body = fmt.Sprintf("Notes %d on Go, Python and Rust (with a bit of PostgreSQL).", i)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return post
+}
+
+// queryBenchmarkPosts runs a query and reads every row it returns.
+func queryBenchmarkPosts(conn *pgxpool.Pool, b *testing.B, stmt string, args ...interface{}) {
+	b.Helper()
+
+	rows, err := conn.Query(context.Background(), stmt, args...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		_, err := rows.Values()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		b.Fatal(err)
+	}
+}
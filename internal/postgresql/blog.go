@@ -4,20 +4,26 @@ import (
 	"context"
 	"errors"
 
+	"github.com/theandrew168/bloggulus/internal/canonical"
 	"github.com/theandrew168/bloggulus/internal/core"
 )
 
 func (s *storage) CreateBlog(ctx context.Context, blog *core.Blog) error {
+	// feeds are fetched as given but deduplicated by their canonical URL
 	stmt := `
 		INSERT INTO blog
-			(feed_url, site_url, title)
+			(feed_url, feed_key, site_url, title, etag, last_modified, ttl)
 		VALUES
-			($1, $2, $3)
+			($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id`
 	args := []interface{}{
 		blog.FeedURL,
+		canonical.URL(blog.FeedURL),
 		blog.SiteURL,
 		blog.Title,
+		blog.ETag,
+		blog.LastModified,
+		blog.TTL,
 	}
 	row := s.conn.QueryRow(ctx, stmt, args...)
 
@@ -38,7 +44,11 @@ func (s *storage) ReadBlog(ctx context.Context, id int) (core.Blog, error) {
 			id,
 			feed_url,
 			site_url,
-			title
+			title,
+			etag,
+			last_modified,
+			ttl,
+			next_sync_at
 		FROM blog
 		WHERE id = $1`
 	row := s.conn.QueryRow(ctx, stmt, id)
@@ -50,6 +60,10 @@ func (s *storage) ReadBlog(ctx context.Context, id int) (core.Blog, error) {
 		&blog.FeedURL,
 		&blog.SiteURL,
 		&blog.Title,
+		&blog.ETag,
+		&blog.LastModified,
+		&blog.TTL,
+		&blog.NextSyncAt,
 	)
 	if err != nil {
 		if errors.Is(err, core.ErrRetry) {
@@ -67,7 +81,11 @@ func (s *storage) ReadBlogs(ctx context.Context, limit, offset int) ([]core.Blog
 			id,
 			feed_url,
 			site_url,
-			title
+			title,
+			etag,
+			last_modified,
+			ttl,
+			next_sync_at
 		FROM blog
 		ORDER BY title ASC
 		LIMIT $1 OFFSET $2`
@@ -87,6 +105,10 @@ func (s *storage) ReadBlogs(ctx context.Context, limit, offset int) ([]core.Blog
 			&blog.FeedURL,
 			&blog.SiteURL,
 			&blog.Title,
+			&blog.ETag,
+			&blog.LastModified,
+			&blog.TTL,
+			&blog.NextSyncAt,
 		)
 		if err != nil {
 			if errors.Is(err, core.ErrRetry) {
@@ -100,3 +122,241 @@ func (s *storage) ReadBlogs(ctx context.Context, limit, offset int) ([]core.Blog
 
 	return blogs, nil
 }
+
+func (s *storage) ReadBlogStats(ctx context.Context, limit, offset int) ([]core.BlogStats, error) {
+	stmt := `
+		SELECT
+			blog.id,
+			blog.feed_url,
+			blog.site_url,
+			blog.title,
+			count(post.id),
+			max(post.updated)
+		FROM blog
+		LEFT JOIN post
+			ON post.blog_id = blog.id
+		GROUP BY blog.id
+		ORDER BY blog.title ASC
+		LIMIT $1 OFFSET $2`
+	rows, err := s.conn.Query(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// use make here to JSON encode as an empty array instead of null
+	stats := make([]core.BlogStats, 0)
+	for rows.Next() {
+		var stat core.BlogStats
+		err := scan(
+			rows,
+			&stat.ID,
+			&stat.FeedURL,
+			&stat.SiteURL,
+			&stat.Title,
+			&stat.PostCount,
+			&stat.LastPost,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadBlogStats(ctx, limit, offset)
+			}
+			return nil, err
+		}
+
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+func (s *storage) ReadFailingBlogs(ctx context.Context, limit, offset int) ([]core.Blog, error) {
+	stmt := `
+		SELECT
+			blog.id,
+			blog.feed_url,
+			blog.site_url,
+			blog.title,
+			blog.etag,
+			blog.last_modified,
+			blog.ttl,
+			blog.next_sync_at
+		FROM blog
+		INNER JOIN sync_status
+			ON sync_status.blog_id = blog.id
+		WHERE sync_status.failure_count > 0
+		ORDER BY blog.title ASC
+		LIMIT $1 OFFSET $2`
+	rows, err := s.conn.Query(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := make([]core.Blog, 0)
+	for rows.Next() {
+		var blog core.Blog
+		err := scan(
+			rows,
+			&blog.ID,
+			&blog.FeedURL,
+			&blog.SiteURL,
+			&blog.Title,
+			&blog.ETag,
+			&blog.LastModified,
+			&blog.TTL,
+			&blog.NextSyncAt,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadFailingBlogs(ctx, limit, offset)
+			}
+			return nil, err
+		}
+
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, nil
+}
+
+func (s *storage) ReadBlogsDueForSync(ctx context.Context, afterID, limit int) ([]core.Blog, error) {
+	stmt := `
+		SELECT
+			id,
+			feed_url,
+			site_url,
+			title,
+			etag,
+			last_modified,
+			ttl,
+			next_sync_at
+		FROM blog
+		WHERE next_sync_at <= NOW()
+		AND id > $1
+		ORDER BY id ASC
+		LIMIT $2`
+	rows, err := s.conn.Query(ctx, stmt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := make([]core.Blog, 0)
+	for rows.Next() {
+		var blog core.Blog
+		err := scan(
+			rows,
+			&blog.ID,
+			&blog.FeedURL,
+			&blog.SiteURL,
+			&blog.Title,
+			&blog.ETag,
+			&blog.LastModified,
+			&blog.TTL,
+			&blog.NextSyncAt,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadBlogsDueForSync(ctx, afterID, limit)
+			}
+			return nil, err
+		}
+
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, nil
+}
+
+func (s *storage) UpdateBlog(ctx context.Context, blog core.Blog) error {
+	stmt := `
+		UPDATE blog
+		SET
+			feed_url = $2,
+			feed_key = $3,
+			site_url = $4,
+			title = $5,
+			etag = $6,
+			last_modified = $7,
+			ttl = $8,
+			next_sync_at = $9
+		WHERE id = $1
+		RETURNING id`
+	args := []interface{}{
+		blog.ID,
+		blog.FeedURL,
+		canonical.URL(blog.FeedURL),
+		blog.SiteURL,
+		blog.Title,
+		blog.ETag,
+		blog.LastModified,
+		blog.TTL,
+		blog.NextSyncAt,
+	}
+	row := s.conn.QueryRow(ctx, stmt, args...)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdateBlog(ctx, blog)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateBlogSchedule saves the fields written by each sync (cache
+// validators and scheduling) without touching those that admins edit.
+func (s *storage) UpdateBlogSchedule(ctx context.Context, blog core.Blog) error {
+	stmt := `
+		UPDATE blog
+		SET
+			etag = $2,
+			last_modified = $3,
+			ttl = $4,
+			next_sync_at = $5
+		WHERE id = $1
+		RETURNING id`
+	args := []interface{}{
+		blog.ID,
+		blog.ETag,
+		blog.LastModified,
+		blog.TTL,
+		blog.NextSyncAt,
+	}
+	row := s.conn.QueryRow(ctx, stmt, args...)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdateBlogSchedule(ctx, blog)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) DeleteBlog(ctx context.Context, id int) error {
+	// posts (and their sync status) are removed via ON DELETE CASCADE
+	stmt := `
+		DELETE FROM blog
+		WHERE id = $1
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteBlog(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
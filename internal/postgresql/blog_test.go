@@ -23,6 +23,14 @@ func TestCreateBlogAlreadyExists(t *testing.T) {
 	test.CreateBlogAlreadyExists(storage, t)
 }
 
+func TestCreateBlogCanonical(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateBlogCanonical(storage, t)
+}
+
 func TestReadBlog(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
@@ -38,3 +46,67 @@ func TestReadBlogs(t *testing.T) {
 	storage := postgresql.NewStorage(conn)
 	test.ReadBlogs(storage, t)
 }
+
+func TestReadBlogStats(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadBlogStats(storage, t)
+}
+
+func TestReadFailingBlogs(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadFailingBlogs(storage, t)
+}
+
+func TestReadBlogsDueForSync(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadBlogsDueForSync(storage, t)
+}
+
+func TestUpdateBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdateBlog(storage, t)
+}
+
+func TestUpdateBlogSchedule(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdateBlogSchedule(storage, t)
+}
+
+func TestUpdateBlogNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdateBlogNotExist(storage, t)
+}
+
+func TestDeleteBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.DeleteBlog(storage, t)
+}
+
+func TestDeleteBlogNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.DeleteBlogNotExist(storage, t)
+}
@@ -0,0 +1,167 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateDigest(ctx context.Context, digest *core.Digest) error {
+	// posts deleted since being sent are quietly left out
+	stmt := `
+		WITH created AS (
+			INSERT INTO digest
+				(since, sent_at)
+			VALUES
+				($1, $2)
+			RETURNING id
+		), included AS (
+			INSERT INTO digest_post
+				(digest_id, post_id)
+			SELECT created.id, post.id
+			FROM created, post
+			WHERE post.id = ANY($3::integer[])
+		)
+		SELECT id
+		FROM created`
+	row := s.conn.QueryRow(ctx, stmt, digest.Since, digest.SentAt, digest.PostIDs)
+
+	err := scan(row, &digest.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateDigest(ctx, digest)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadLastDigest(ctx context.Context) (core.Digest, error) {
+	stmt := `
+		SELECT
+			digest.id,
+			digest.since,
+			digest.sent_at,
+			array_remove(array_agg(digest_post.post_id ORDER BY digest_post.post_id), NULL) as post_ids
+		FROM digest
+		LEFT JOIN digest_post
+			ON digest_post.digest_id = digest.id
+		GROUP BY digest.id
+		ORDER BY digest.sent_at DESC, digest.id DESC
+		LIMIT 1`
+	row := s.conn.QueryRow(ctx, stmt)
+
+	var digest core.Digest
+	err := scan(
+		row,
+		&digest.ID,
+		&digest.Since,
+		&digest.SentAt,
+		&digest.PostIDs,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadLastDigest(ctx)
+		}
+		return core.Digest{}, err
+	}
+
+	return digest, nil
+}
+
+func (s *storage) ReadUndigestedPosts(ctx context.Context, search core.SearchRequest, since time.Time, limit int) ([]core.Post, error) {
+	// the earliest posts are picked (but listed newest first) so that the
+	// rest are left for the next read
+	stmt := `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post
+			INNER JOIN blog
+				ON blog.id = post.blog_id` + filterPostsWhere + `
+				AND post.first_seen >= $6
+				AND NOT EXISTS (
+					SELECT 1
+					FROM digest_post
+					WHERE digest_post.post_id = post.id
+				)
+			ORDER BY post.first_seen ASC, post.id ASC
+			LIMIT $7
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY posts.updated DESC, posts.id DESC`
+
+	args := append(searchArgs(search), since, limit)
+	rows, err := s.conn.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadUndigestedPosts(ctx, search, since, limit)
+			}
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,32 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestCreateDigest(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateDigest(storage, t)
+}
+
+func TestReadLastDigest(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadLastDigest(storage, t)
+}
+
+func TestReadUndigestedPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadUndigestedPosts(storage, t)
+}
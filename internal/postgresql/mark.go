@@ -0,0 +1,258 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) MarkPostRead(ctx context.Context, accountID, postID int) error {
+	stmt := `
+		INSERT INTO read_post
+			(account_id, post_id)
+		SELECT $1, post.id
+		FROM post
+		WHERE post.id = $2
+		ON CONFLICT (account_id, post_id) DO UPDATE
+			SET read_at = NOW()
+		RETURNING post_id`
+	row := s.conn.QueryRow(ctx, stmt, accountID, postID)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.MarkPostRead(ctx, accountID, postID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) SavePost(ctx context.Context, accountID, postID int) error {
+	stmt := `
+		INSERT INTO saved_post
+			(account_id, post_id)
+		SELECT $1, post.id
+		FROM post
+		WHERE post.id = $2
+		RETURNING post_id`
+	row := s.conn.QueryRow(ctx, stmt, accountID, postID)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.SavePost(ctx, accountID, postID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) UnsavePost(ctx context.Context, accountID, postID int) error {
+	stmt := `
+		DELETE FROM saved_post
+		WHERE account_id = $1
+			AND post_id = $2
+		RETURNING post_id`
+	row := s.conn.QueryRow(ctx, stmt, accountID, postID)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UnsavePost(ctx, accountID, postID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadSavedPostsAfter(ctx context.Context, accountID int, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM saved_post
+			INNER JOIN post
+				ON post.id = saved_post.post_id
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			WHERE saved_post.account_id = $1
+				AND ($2::timestamptz IS NULL OR (post.updated, post.id) < ($2::timestamptz, $3::integer))
+			ORDER BY post.updated DESC, post.id DESC
+			LIMIT $4
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY posts.updated DESC, posts.id DESC`
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, accountID, cursorUpdated(cursor), cursor.ID, limit+1)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadSavedPostsAfter(ctx, accountID, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		post.Saved = true
+		posts = append(posts, post)
+	}
+
+	posts, next := nextPage(posts, nil, limit)
+	return posts, next, nil
+}
+
+func (s *storage) ReadPostMarks(ctx context.Context, accountID int, posts []core.Post) error {
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	stmt := `
+		SELECT
+			ids.id,
+			EXISTS (
+				SELECT 1
+				FROM read_post
+				WHERE account_id = $1
+					AND post_id = ids.id
+			) AS read,
+			EXISTS (
+				SELECT 1
+				FROM saved_post
+				WHERE account_id = $1
+					AND post_id = ids.id
+			) AS saved
+		FROM unnest($2::integer[]) AS ids (id)`
+	rows, err := s.conn.Query(ctx, stmt, accountID, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type mark struct {
+		read  bool
+		saved bool
+	}
+
+	marks := make(map[int]mark)
+	for rows.Next() {
+		var id int
+		var m mark
+		err := scan(rows, &id, &m.read, &m.saved)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadPostMarks(ctx, accountID, posts)
+			}
+			return err
+		}
+
+		marks[id] = m
+	}
+
+	for i := range posts {
+		posts[i].Read = marks[posts[i].ID].read
+		posts[i].Saved = marks[posts[i].ID].saved
+	}
+
+	return nil
+}
+
+func (s *storage) CountUnreadPosts(ctx context.Context, accountID int) (map[int]int, error) {
+	stmt := `
+		SELECT
+			subscription.blog_id,
+			count(post.id) FILTER (WHERE read_post.post_id IS NULL) AS unread
+		FROM subscription
+		LEFT JOIN post
+			ON post.blog_id = subscription.blog_id
+		LEFT JOIN read_post
+			ON read_post.post_id = post.id
+			AND read_post.account_id = subscription.account_id
+		WHERE subscription.account_id = $1
+		GROUP BY subscription.blog_id`
+	rows, err := s.conn.Query(ctx, stmt, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var blogID, unread int
+		err := scan(rows, &blogID, &unread)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.CountUnreadPosts(ctx, accountID)
+			}
+			return nil, err
+		}
+
+		counts[blogID] = unread
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,80 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestMarkPostRead(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.MarkPostRead(storage, t)
+}
+
+func TestMarkPostReadNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.MarkPostReadNotExist(storage, t)
+}
+
+func TestSavePost(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.SavePost(storage, t)
+}
+
+func TestSavePostAlreadyExists(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.SavePostAlreadyExists(storage, t)
+}
+
+func TestSavePostNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.SavePostNotExist(storage, t)
+}
+
+func TestUnsavePost(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UnsavePost(storage, t)
+}
+
+func TestReadSavedPostsAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSavedPostsAfter(storage, t)
+}
+
+func TestReadPostMarks(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadPostMarks(storage, t)
+}
+
+func TestCountUnreadPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CountUnreadPosts(storage, t)
+}
@@ -3,27 +3,53 @@ package postgresql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html"
+	"strings"
 
+	"github.com/theandrew168/bloggulus/internal/canonical"
 	"github.com/theandrew168/bloggulus/internal/core"
 )
 
 func (s *storage) CreatePost(ctx context.Context, post *core.Post) error {
+	// the post and its tags are written together
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// links are kept as given but deduplicated by their canonical URL
 	stmt := `
 		INSERT INTO post
-			(url, title, updated, body, blog_id)
+			(url, url_key, guid, title, updated, published, author, summary, body, body_html, body_source, blog_id)
 		VALUES
-			($1, $2, $3, $4, $5)
-		RETURNING id`
+			($1, $2, $3, $4, COALESCE($5, NOW()), $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, updated, first_seen`
+
+	// undated posts are sorted by when they were first seen
+	var updated interface{}
+	if !post.Updated.IsZero() {
+		updated = post.Updated
+	}
+
 	args := []interface{}{
 		post.URL,
+		canonical.URL(post.URL),
+		post.GUID,
 		post.Title,
-		post.Updated,
+		updated,
+		post.Published,
+		post.Author,
+		post.Summary,
 		post.Body,
+		post.BodyHTML,
+		post.BodySource,
 		post.Blog.ID,
 	}
-	row := s.conn.QueryRow(ctx, stmt, args...)
+	row := tx.QueryRow(ctx, stmt, args...)
 
-	err := scan(row, &post.ID)
+	err = scan(row, &post.ID, &post.Updated, &post.FirstSeen)
 	if err != nil {
 		if errors.Is(err, core.ErrRetry) {
 			return s.CreatePost(ctx, post)
@@ -31,7 +57,16 @@ func (s *storage) CreatePost(ctx context.Context, post *core.Post) error {
 		return err
 	}
 
-	return nil
+	// assign tags once up front rather than on every read
+	err = tagPost(ctx, tx, post.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreatePost(ctx, post)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (s *storage) ReadPost(ctx context.Context, id int) (core.Post, error) {
@@ -39,9 +74,14 @@ func (s *storage) ReadPost(ctx context.Context, id int) (core.Post, error) {
 		SELECT
 			post.id,
 			post.url,
+			post.guid,
 			post.title,
 			post.updated,
-			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(post.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			post.published,
+			post.author,
+			post.first_seen,
+			post.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
 			blog.id,
 			blog.feed_url,
 			blog.site_url,
@@ -49,10 +89,12 @@ func (s *storage) ReadPost(ctx context.Context, id int) (core.Post, error) {
 		FROM post
 		INNER JOIN blog
 			ON blog.id = post.blog_id
+		LEFT JOIN post_tag
+			ON post_tag.post_id = post.id
 		LEFT JOIN tag
-			ON to_tsquery(tag.name) @@ post.content_index
+			ON tag.id = post_tag.tag_id
 		WHERE post.id = $1
-		GROUP BY 1,2,3,4,6,7,8,9`
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14`
 	row := s.conn.QueryRow(ctx, stmt, id)
 
 	var post core.Post
@@ -60,8 +102,13 @@ func (s *storage) ReadPost(ctx context.Context, id int) (core.Post, error) {
 		row,
 		&post.ID,
 		&post.URL,
+		&post.GUID,
 		&post.Title,
 		&post.Updated,
+		&post.Published,
+		&post.Author,
+		&post.FirstSeen,
+		&post.Summary,
 		&post.Tags,
 		&post.Blog.ID,
 		&post.Blog.FeedURL,
@@ -78,15 +125,100 @@ func (s *storage) ReadPost(ctx context.Context, id int) (core.Post, error) {
 	return post, nil
 }
 
+func (s *storage) UpdatePost(ctx context.Context, post core.Post) error {
+	// the post and its tags are written together
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		UPDATE post
+		SET
+			url = $2,
+			url_key = $3,
+			guid = $4,
+			title = $5,
+			updated = $6,
+			published = $7,
+			author = $8,
+			summary = $9,
+			body = $10,
+			body_html = $11,
+			body_source = $12
+		WHERE id = $1
+		RETURNING id`
+	args := []interface{}{
+		post.ID,
+		post.URL,
+		canonical.URL(post.URL),
+		post.GUID,
+		post.Title,
+		post.Updated,
+		post.Published,
+		post.Author,
+		post.Summary,
+		post.Body,
+		post.BodyHTML,
+		post.BodySource,
+	}
+	row := tx.QueryRow(ctx, stmt, args...)
+
+	var id int
+	err = scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdatePost(ctx, post)
+		}
+		return err
+	}
+
+	// the post's content may now mention different tags
+	err = tagPost(ctx, tx, post.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdatePost(ctx, post)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *storage) UpdatePostGUID(ctx context.Context, id int, guid string) error {
+	stmt := `
+		UPDATE post
+		SET guid = $2
+		WHERE id = $1
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, id, guid)
+
+	var updated int
+	err := scan(row, &updated)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdatePostGUID(ctx, id, guid)
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (s *storage) ReadPosts(ctx context.Context, limit, offset int) ([]core.Post, error) {
 	stmt := `
 		WITH posts AS (
 			SELECT
 				post.id,
 				post.url,
+				post.guid,
 				post.title,
 				post.updated,
-				post.content_index,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
 				blog.id AS blog_id,
 				blog.feed_url AS blog_feed_url,
 				blog.site_url AS blog_site_url,
@@ -100,17 +232,24 @@ func (s *storage) ReadPosts(ctx context.Context, limit, offset int) ([]core.Post
 		SELECT
 			posts.id,
 			posts.url,
+			posts.guid,
 			posts.title,
 			posts.updated,
-			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(posts.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
 			posts.blog_id,
 			posts.blog_feed_url,
 			posts.blog_site_url,
 			posts.blog_title
 		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
 		LEFT JOIN tag
-			ON to_tsquery(tag.name) @@ posts.content_index
-		GROUP BY 1,2,3,4,6,7,8,9
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
 		ORDER BY posts.updated DESC`
 	rows, err := s.conn.Query(ctx, stmt, limit, offset)
 	if err != nil {
@@ -126,8 +265,13 @@ func (s *storage) ReadPosts(ctx context.Context, limit, offset int) ([]core.Post
 			rows,
 			&post.ID,
 			&post.URL,
+			&post.GUID,
 			&post.Title,
 			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
 			&post.Tags,
 			&post.Blog.ID,
 			&post.Blog.FeedURL,
@@ -152,9 +296,14 @@ func (s *storage) ReadPostsByBlog(ctx context.Context, blogID int, limit, offset
 		SELECT
 			post.id,
 			post.url,
+			post.guid,
 			post.title,
 			post.updated,
-			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(post.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			post.published,
+			post.author,
+			post.first_seen,
+			post.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
 			blog.id,
 			blog.feed_url,
 			blog.site_url,
@@ -162,10 +311,12 @@ func (s *storage) ReadPostsByBlog(ctx context.Context, blogID int, limit, offset
 		FROM post
 		INNER JOIN blog
 			ON blog.id = post.blog_id
+		LEFT JOIN post_tag
+			ON post_tag.post_id = post.id
 		LEFT JOIN tag
-			ON to_tsquery(tag.name) @@ post.content_index
+			ON tag.id = post_tag.tag_id
 		WHERE blog.id = $1
-		GROUP BY 1,2,3,4,6,7,8,9
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
 		ORDER BY post.updated DESC
 		LIMIT $2 OFFSET $3`
 	rows, err := s.conn.Query(ctx, stmt, blogID, limit, offset)
@@ -181,8 +332,13 @@ func (s *storage) ReadPostsByBlog(ctx context.Context, blogID int, limit, offset
 			rows,
 			&post.ID,
 			&post.URL,
+			&post.GUID,
 			&post.Title,
 			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
 			&post.Tags,
 			&post.Blog.ID,
 			&post.Blog.FeedURL,
@@ -208,8 +364,14 @@ func (s *storage) SearchPosts(ctx context.Context, query string, limit, offset i
 			SELECT
 				post.id,
 				post.url,
+				post.guid,
 				post.title,
 				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				post.body,
 				post.content_index,
 				blog.id AS blog_id,
 				blog.feed_url AS blog_feed_url,
@@ -225,19 +387,27 @@ func (s *storage) SearchPosts(ctx context.Context, query string, limit, offset i
 		SELECT
 			posts.id,
 			posts.url,
+			posts.guid,
 			posts.title,
 			posts.updated,
-			array_remove(array_agg(tag.name ORDER BY ts_rank_cd(posts.content_index, to_tsquery(tag.name)) DESC), NULL) as tags,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
 			posts.blog_id,
 			posts.blog_feed_url,
 			posts.blog_site_url,
-			posts.blog_title
+			posts.blog_title,
+			ts_headline('english', posts.body, websearch_to_tsquery('english',  $1), $4)
 		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
 		LEFT JOIN tag
-			ON to_tsquery(tag.name) @@ content_index
-		GROUP BY 1,2,3,4,6,7,8,9,posts.content_index
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14,15,posts.content_index
 		ORDER BY ts_rank_cd(posts.content_index, websearch_to_tsquery('english',  $1)) DESC`
-	rows, err := s.conn.Query(ctx, stmt, query, limit, offset)
+	rows, err := s.conn.Query(ctx, stmt, query, limit, offset, headlineOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -250,13 +420,19 @@ func (s *storage) SearchPosts(ctx context.Context, query string, limit, offset i
 			rows,
 			&post.ID,
 			&post.URL,
+			&post.GUID,
 			&post.Title,
 			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
 			&post.Tags,
 			&post.Blog.ID,
 			&post.Blog.FeedURL,
 			&post.Blog.SiteURL,
 			&post.Blog.Title,
+			&post.Snippet,
 		)
 		if err != nil {
 			if errors.Is(err, core.ErrRetry) {
@@ -265,12 +441,518 @@ func (s *storage) SearchPosts(ctx context.Context, query string, limit, offset i
 			return nil, err
 		}
 
+		post.Snippet = highlight(post.Snippet)
 		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
 
+func (s *storage) ReadPostsAfter(ctx context.Context, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			WHERE $1::timestamptz IS NULL OR (post.updated, post.id) < ($1::timestamptz, $2::integer)
+			ORDER BY post.updated DESC, post.id DESC
+			LIMIT $3
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY posts.updated DESC, posts.id DESC`
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, cursorUpdated(cursor), cursor.ID, limit+1)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadPostsAfter(ctx, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	posts, next := nextPage(posts, nil, limit)
+	return posts, next, nil
+}
+
+func (s *storage) ReadPostsByBlogAfter(ctx context.Context, blogID int, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		SELECT
+			post.id,
+			post.url,
+			post.guid,
+			post.title,
+			post.updated,
+			post.published,
+			post.author,
+			post.first_seen,
+			post.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			blog.id,
+			blog.feed_url,
+			blog.site_url,
+			blog.title
+		FROM post
+		INNER JOIN blog
+			ON blog.id = post.blog_id
+		LEFT JOIN post_tag
+			ON post_tag.post_id = post.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		WHERE blog.id = $1
+			AND ($2::timestamptz IS NULL OR (post.updated, post.id) < ($2::timestamptz, $3::integer))
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY post.updated DESC, post.id DESC
+		LIMIT $4`
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, blogID, cursorUpdated(cursor), cursor.ID, limit+1)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadPostsByBlogAfter(ctx, blogID, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	posts, next := nextPage(posts, nil, limit)
+	return posts, next, nil
+}
+
+func (s *storage) ReadPostsByTagAfter(ctx context.Context, tagID int, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post_tag
+			INNER JOIN post
+				ON post.id = post_tag.post_id
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			WHERE post_tag.tag_id = $1
+				AND ($2::timestamptz IS NULL OR (post.updated, post.id) < ($2::timestamptz, $3::integer))
+			ORDER BY post.updated DESC, post.id DESC
+			LIMIT $4
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY posts.updated DESC, posts.id DESC`
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, tagID, cursorUpdated(cursor), cursor.ID, limit+1)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadPostsByTagAfter(ctx, tagID, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	posts, next := nextPage(posts, nil, limit)
+	return posts, next, nil
+}
+
+func (s *storage) ReadSubscribedPostsAfter(ctx context.Context, accountID int, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		WITH posts AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM subscription
+			INNER JOIN post
+				ON post.blog_id = subscription.blog_id
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			WHERE subscription.account_id = $1
+				AND ($2::timestamptz IS NULL OR (post.updated, post.id) < ($2::timestamptz, $3::integer))
+			ORDER BY post.updated DESC, post.id DESC
+			LIMIT $4
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14
+		ORDER BY posts.updated DESC, posts.id DESC`
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, accountID, cursorUpdated(cursor), cursor.ID, limit+1)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	for rows.Next() {
+		var post core.Post
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadSubscribedPostsAfter(ctx, accountID, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	posts, next := nextPage(posts, nil, limit)
+	return posts, next, nil
+}
+
+func (s *storage) SearchPostsAfter(ctx context.Context, query string, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	stmt := `
+		WITH ranked AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				post.body,
+				ts_rank_cd(post.content_index, websearch_to_tsquery('english',  $1)) AS rank,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post
+			INNER JOIN blog
+				ON blog.id = post.blog_id
+			WHERE post.content_index @@ websearch_to_tsquery('english',  $1)
+		), posts AS (
+			SELECT *
+			FROM ranked
+			WHERE $2::real IS NULL OR (ranked.rank, ranked.id) < ($2::real, $3::integer)
+			ORDER BY ranked.rank DESC, ranked.id DESC
+			LIMIT $4
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title,
+			ts_headline('english', posts.body, websearch_to_tsquery('english',  $1), $5),
+			posts.rank
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14,15,16
+		ORDER BY posts.rank DESC, posts.id DESC`
+
+	// a search cursor is positioned by rank rather than by date
+	var rank interface{}
+	if !cursor.IsZero() {
+		rank = cursor.Rank
+	}
+
+	// read one extra post to determine if another page exists
+	rows, err := s.conn.Query(ctx, stmt, query, rank, cursor.ID, limit+1, headlineOptions)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	ranks := make([]float32, 0)
+	for rows.Next() {
+		var post core.Post
+		var rank float32
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+			&post.Snippet,
+			&rank,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.SearchPostsAfter(ctx, query, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		post.Snippet = highlight(post.Snippet)
+		posts = append(posts, post)
+		ranks = append(ranks, rank)
+	}
+
+	posts, next := nextPage(posts, ranks, limit)
+	return posts, next, nil
+}
+
+// ts_headline marks matches with control characters (rather than HTML) so
+// that the rest of the snippet can be escaped before it is highlighted
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var headlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+	headlineStart, headlineStop)
+
+// highlight converts a ts_headline snippet into HTML with each matched
+// term wrapped in <mark>.
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, headlineStart, "<mark>")
+	snippet = strings.ReplaceAll(snippet, headlineStop, "</mark>")
+	return snippet
+}
+
+// cursorUpdated returns the cursor's timestamp or NULL for the first page.
+func cursorUpdated(cursor core.Cursor) interface{} {
+	if cursor.IsZero() {
+		return nil
+	}
+	return cursor.Updated
+}
+
+// nextPage drops the extra lookahead post (if present) and builds the
+// cursor pointing just past the last post that remains. Search results
+// also supply each post's rank.
+func nextPage(posts []core.Post, ranks []float32, limit int) ([]core.Post, core.Cursor) {
+	if len(posts) <= limit {
+		return posts, core.Cursor{}
+	}
+
+	posts = posts[:limit]
+	if limit == 0 {
+		return posts, core.Cursor{}
+	}
+
+	last := len(posts) - 1
+
+	next := core.Cursor{
+		Updated: posts[last].Updated,
+		ID:      posts[last].ID,
+	}
+	if ranks != nil {
+		next = core.Cursor{
+			Rank: ranks[last],
+			ID:   posts[last].ID,
+		}
+	}
+
+	return posts, next
+}
+
 func (s *storage) CountPosts(ctx context.Context) (int, error) {
 	stmt := `
 		SELECT count(*)
@@ -289,6 +971,25 @@ func (s *storage) CountPosts(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+func (s *storage) CountPostsByBlog(ctx context.Context, blogID int) (int, error) {
+	stmt := `
+		SELECT count(*)
+		FROM post
+		WHERE blog_id = $1`
+	row := s.conn.QueryRow(ctx, stmt, blogID)
+
+	var count int
+	err := scan(row, &count)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CountPostsByBlog(ctx, blogID)
+		}
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (s *storage) CountSearchPosts(ctx context.Context, query string) (int, error) {
 	stmt := `
 		SELECT count(*)
@@ -23,6 +23,22 @@ func TestCreatePostAlreadyExists(t *testing.T) {
 	test.CreatePostAlreadyExists(storage, t)
 }
 
+func TestCreatePostGUIDAlreadyExists(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreatePostGUIDAlreadyExists(storage, t)
+}
+
+func TestCreatePostCanonical(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreatePostCanonical(storage, t)
+}
+
 func TestReadPost(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
@@ -31,6 +47,38 @@ func TestReadPost(t *testing.T) {
 	test.ReadPost(storage, t)
 }
 
+func TestUpdatePost(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdatePost(storage, t)
+}
+
+func TestUpdatePostNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdatePostNotExist(storage, t)
+}
+
+func TestUpdatePostGUID(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdatePostGUID(storage, t)
+}
+
+func TestUpdatePostGUIDNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdatePostGUIDNotExist(storage, t)
+}
+
 func TestReadPosts(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
@@ -55,6 +103,62 @@ func TestSearchPosts(t *testing.T) {
 	test.SearchPosts(storage, t)
 }
 
+func TestReadPostsAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadPostsAfter(storage, t)
+}
+
+func TestReadPostsByBlogAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadPostsByBlogAfter(storage, t)
+}
+
+func TestReadPostsByTagAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadPostsByTagAfter(storage, t)
+}
+
+func TestReadPostPublished(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadPostPublished(storage, t)
+}
+
+func TestCreatePostUndated(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreatePostUndated(storage, t)
+}
+
+func TestSearchPostsSnippet(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.SearchPostsSnippet(storage, t)
+}
+
+func TestSearchPostsAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.SearchPostsAfter(storage, t)
+}
+
 func TestCountPosts(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
@@ -63,6 +167,14 @@ func TestCountPosts(t *testing.T) {
 	test.CountPosts(storage, t)
 }
 
+func TestCountPostsByBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CountPostsByBlog(storage, t)
+}
+
 func TestCountSearchPosts(t *testing.T) {
 	conn := test.ConnectDB(t)
 	defer conn.Close()
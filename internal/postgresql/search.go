@@ -0,0 +1,230 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+// filterPostsWhere matches posts against a search request. The request
+// takes up parameters $1 through $5 (see searchArgs).
+const filterPostsWhere = `
+	WHERE ($1::text = '' OR post.content_index @@ websearch_to_tsquery('english', $1::text))
+		AND (cardinality($2::integer[]) = 0 OR post.blog_id = ANY($2::integer[]))
+		AND NOT EXISTS (
+			SELECT 1
+			FROM unnest($3::text[]) AS wanted (name)
+			WHERE NOT EXISTS (
+				SELECT 1
+				FROM post_tag
+				INNER JOIN tag_term
+					ON tag_term.tag_id = post_tag.tag_id
+				WHERE post_tag.post_id = post.id
+					AND lower(tag_term.name) = lower(wanted.name)
+			)
+		)
+		AND ($4::timestamptz IS NULL OR post.updated >= $4::timestamptz)
+		AND ($5::timestamptz IS NULL OR post.updated < $5::timestamptz)`
+
+func (s *storage) FilterPosts(ctx context.Context, search core.SearchRequest, cursor core.Cursor, limit int) ([]core.Post, core.Cursor, error) {
+	// page by rank for relevance and by date otherwise
+	page := `
+		WHERE $6::timestamptz IS NULL OR (matched.updated, matched.id) < ($6::timestamptz, $7::integer)
+		ORDER BY matched.updated DESC, matched.id DESC`
+	order := `
+		ORDER BY posts.updated DESC, posts.id DESC`
+	var position interface{} = cursorUpdated(cursor)
+	if search.ByRelevance() {
+		page = `
+		WHERE $6::real IS NULL OR (matched.rank, matched.id) < ($6::real, $7::integer)
+		ORDER BY matched.rank DESC, matched.id DESC`
+		order = `
+		ORDER BY posts.rank DESC, posts.id DESC`
+		position = nil
+		if !cursor.IsZero() {
+			position = cursor.Rank
+		}
+	}
+
+	stmt := `
+		WITH matched AS (
+			SELECT
+				post.id,
+				post.url,
+				post.guid,
+				post.title,
+				post.updated,
+				post.published,
+				post.author,
+				post.first_seen,
+				post.summary,
+				post.body,
+				CASE WHEN $1::text = '' THEN 0 ELSE ts_rank_cd(post.content_index, websearch_to_tsquery('english', $1::text)) END AS rank,
+				blog.id AS blog_id,
+				blog.feed_url AS blog_feed_url,
+				blog.site_url AS blog_site_url,
+				blog.title AS blog_title
+			FROM post
+			INNER JOIN blog
+				ON blog.id = post.blog_id` + filterPostsWhere + `
+		), posts AS (
+			SELECT *
+			FROM matched` + page + `
+			LIMIT $8
+		)
+		SELECT
+			posts.id,
+			posts.url,
+			posts.guid,
+			posts.title,
+			posts.updated,
+			posts.published,
+			posts.author,
+			posts.first_seen,
+			posts.summary,
+			array_remove(array_agg(tag.name ORDER BY post_tag.rank DESC, tag.name), NULL) as tags,
+			posts.blog_id,
+			posts.blog_feed_url,
+			posts.blog_site_url,
+			posts.blog_title,
+			CASE WHEN $1::text = '' THEN '' ELSE ts_headline('english', posts.body, websearch_to_tsquery('english', $1::text), $9) END,
+			posts.rank
+		FROM posts
+		LEFT JOIN post_tag
+			ON post_tag.post_id = posts.id
+		LEFT JOIN tag
+			ON tag.id = post_tag.tag_id
+		GROUP BY 1,2,3,4,5,6,7,8,9,11,12,13,14,15,16` + order
+
+	// read one extra post to determine if another page exists
+	args := append(searchArgs(search), position, cursor.ID, limit+1, headlineOptions)
+	rows, err := s.conn.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, core.Cursor{}, err
+	}
+	defer rows.Close()
+
+	posts := make([]core.Post, 0)
+	var ranks []float32
+	for rows.Next() {
+		var post core.Post
+		var rank float32
+		err := scan(
+			rows,
+			&post.ID,
+			&post.URL,
+			&post.GUID,
+			&post.Title,
+			&post.Updated,
+			&post.Published,
+			&post.Author,
+			&post.FirstSeen,
+			&post.Summary,
+			&post.Tags,
+			&post.Blog.ID,
+			&post.Blog.FeedURL,
+			&post.Blog.SiteURL,
+			&post.Blog.Title,
+			&post.Snippet,
+			&rank,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.FilterPosts(ctx, search, cursor, limit)
+			}
+			return nil, core.Cursor{}, err
+		}
+
+		post.Snippet = highlight(post.Snippet)
+		posts = append(posts, post)
+		ranks = append(ranks, rank)
+	}
+
+	// date ordered pages are positioned by date alone
+	if !search.ByRelevance() {
+		ranks = nil
+	}
+
+	posts, next := nextPage(posts, ranks, limit)
+	return posts, next, nil
+}
+
+func (s *storage) MatchPosts(ctx context.Context, search core.SearchRequest, postIDs []int) ([]int, error) {
+	stmt := `
+		SELECT post.id
+		FROM post` + filterPostsWhere + `
+			AND post.id = ANY($6::integer[])
+		ORDER BY post.id ASC`
+
+	args := append(searchArgs(search), postIDs)
+	rows, err := s.conn.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		err := scan(rows, &id)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.MatchPosts(ctx, search, postIDs)
+			}
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (s *storage) CountFilteredPosts(ctx context.Context, search core.SearchRequest) (int, error) {
+	stmt := `
+		SELECT count(*)
+		FROM post` + filterPostsWhere
+	row := s.conn.QueryRow(ctx, stmt, searchArgs(search)...)
+
+	var count int
+	err := scan(row, &count)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CountFilteredPosts(ctx, search)
+		}
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// searchArgs returns the parameters used by filterPostsWhere.
+func searchArgs(search core.SearchRequest) []interface{} {
+	// empty (rather than nil) arrays don't filter anything
+	blogIDs := search.BlogIDs
+	if blogIDs == nil {
+		blogIDs = []int{}
+	}
+	tags := search.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return []interface{}{
+		search.Text,
+		blogIDs,
+		tags,
+		nullTime(search.Since),
+		nullTime(search.Until),
+	}
+}
+
+// nullTime returns NULL for the zero time.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
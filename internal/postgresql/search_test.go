@@ -0,0 +1,32 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestFilterPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.FilterPosts(storage, t)
+}
+
+func TestFilterPostsAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.FilterPostsAfter(storage, t)
+}
+
+func TestMatchPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.MatchPosts(storage, t)
+}
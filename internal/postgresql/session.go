@@ -0,0 +1,52 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateSession(ctx context.Context, session *core.Session) error {
+	stmt := `
+		WITH expired AS (
+			DELETE FROM session
+			WHERE account_id = $1
+				AND expiry <= NOW()
+		)
+		INSERT INTO session
+			(account_id, hash, expiry)
+		VALUES
+			($1, $2, $3)
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, session.AccountID, session.Hash, session.Expiry)
+
+	err := scan(row, &session.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateSession(ctx, session)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) DeleteSession(ctx context.Context, hash string) error {
+	stmt := `
+		DELETE FROM session
+		WHERE hash = $1
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, hash)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteSession(ctx, hash)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) RecordSyncStatus(ctx context.Context, status *core.SyncStatus) error {
+	// failures accumulate until the next success resets them
+	stmt := `
+		INSERT INTO sync_status
+			(blog_id, last_sync_at, last_success_at, last_error, failure_count, http_status, new_posts, updated_posts)
+		VALUES (
+			$1,
+			$2::timestamptz,
+			CASE WHEN $3::text = '' THEN $2::timestamptz END,
+			$3::text,
+			CASE WHEN $3::text = '' THEN 0 ELSE 1 END,
+			$4,
+			$5,
+			$6
+		)
+		ON CONFLICT (blog_id) DO UPDATE
+		SET
+			last_sync_at = EXCLUDED.last_sync_at,
+			last_success_at = COALESCE(EXCLUDED.last_success_at, sync_status.last_success_at),
+			last_error = EXCLUDED.last_error,
+			failure_count = CASE
+				WHEN EXCLUDED.last_error = '' THEN 0
+				ELSE sync_status.failure_count + 1
+			END,
+			http_status = EXCLUDED.http_status,
+			new_posts = EXCLUDED.new_posts,
+			updated_posts = EXCLUDED.updated_posts
+		RETURNING last_success_at, failure_count`
+	args := []interface{}{
+		status.BlogID,
+		status.LastSyncAt,
+		status.LastError,
+		status.HTTPStatus,
+		status.NewPosts,
+		status.UpdatedPosts,
+	}
+	row := s.conn.QueryRow(ctx, stmt, args...)
+
+	err := scan(row, &status.LastSuccessAt, &status.FailureCount)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.RecordSyncStatus(ctx, status)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadSyncStatus(ctx context.Context, blogID int) (core.SyncStatus, error) {
+	stmt := `
+		SELECT
+			blog_id,
+			last_sync_at,
+			last_success_at,
+			last_error,
+			failure_count,
+			http_status,
+			new_posts,
+			updated_posts
+		FROM sync_status
+		WHERE blog_id = $1`
+	row := s.conn.QueryRow(ctx, stmt, blogID)
+
+	var status core.SyncStatus
+	err := scan(
+		row,
+		&status.BlogID,
+		&status.LastSyncAt,
+		&status.LastSuccessAt,
+		&status.LastError,
+		&status.FailureCount,
+		&status.HTTPStatus,
+		&status.NewPosts,
+		&status.UpdatedPosts,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadSyncStatus(ctx, blogID)
+		}
+		return core.SyncStatus{}, err
+	}
+
+	return status, nil
+}
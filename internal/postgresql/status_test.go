@@ -0,0 +1,32 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestRecordSyncStatus(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.RecordSyncStatus(storage, t)
+}
+
+func TestReadSyncStatus(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSyncStatus(storage, t)
+}
+
+func TestReadSyncStatusNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSyncStatusNotExist(storage, t)
+}
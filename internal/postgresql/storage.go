@@ -1,6 +1,9 @@
 package postgresql
 
 import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/theandrew168/bloggulus/internal/core"
@@ -16,3 +19,10 @@ func NewStorage(conn *pgxpool.Pool) core.Storage {
 	}
 	return &s
 }
+
+// querier is satisfied by both the pool and a transaction so that helpers
+// can run on their own or as part of a larger write.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
@@ -0,0 +1,131 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateSubscription(ctx context.Context, accountID, blogID int) error {
+	stmt := `
+		INSERT INTO subscription
+			(account_id, blog_id)
+		SELECT $1, blog.id
+		FROM blog
+		WHERE blog.id = $2
+		RETURNING blog_id`
+	row := s.conn.QueryRow(ctx, stmt, accountID, blogID)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateSubscription(ctx, accountID, blogID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadSubscriptions(ctx context.Context, accountID int, limit, offset int) ([]core.Subscription, error) {
+	stmt := `
+		SELECT
+			blog.id,
+			blog.feed_url,
+			blog.site_url,
+			blog.title,
+			blog.etag,
+			blog.last_modified,
+			blog.ttl,
+			blog.next_sync_at,
+			count(post.id) FILTER (WHERE read_post.post_id IS NULL) AS unread
+		FROM subscription
+		INNER JOIN blog
+			ON blog.id = subscription.blog_id
+		LEFT JOIN post
+			ON post.blog_id = blog.id
+		LEFT JOIN read_post
+			ON read_post.post_id = post.id
+			AND read_post.account_id = subscription.account_id
+		WHERE subscription.account_id = $1
+		GROUP BY blog.id
+		ORDER BY blog.title ASC
+		LIMIT $2 OFFSET $3`
+	rows, err := s.conn.Query(ctx, stmt, accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// use make here to JSON encode as an empty array instead of null
+	subscriptions := make([]core.Subscription, 0)
+	for rows.Next() {
+		var subscription core.Subscription
+		err := scan(
+			rows,
+			&subscription.ID,
+			&subscription.FeedURL,
+			&subscription.SiteURL,
+			&subscription.Title,
+			&subscription.ETag,
+			&subscription.LastModified,
+			&subscription.TTL,
+			&subscription.NextSyncAt,
+			&subscription.Unread,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadSubscriptions(ctx, accountID, limit, offset)
+			}
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (s *storage) IsSubscribed(ctx context.Context, accountID, blogID int) (bool, error) {
+	stmt := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM subscription
+			WHERE account_id = $1
+				AND blog_id = $2
+		)`
+	row := s.conn.QueryRow(ctx, stmt, accountID, blogID)
+
+	var subscribed bool
+	err := scan(row, &subscribed)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.IsSubscribed(ctx, accountID, blogID)
+		}
+		return false, err
+	}
+
+	return subscribed, nil
+}
+
+func (s *storage) DeleteSubscription(ctx context.Context, accountID, blogID int) error {
+	stmt := `
+		DELETE FROM subscription
+		WHERE account_id = $1
+			AND blog_id = $2
+		RETURNING blog_id`
+	row := s.conn.QueryRow(ctx, stmt, accountID, blogID)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteSubscription(ctx, accountID, blogID)
+		}
+		return err
+	}
+
+	return nil
+}
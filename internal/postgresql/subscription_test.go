@@ -0,0 +1,64 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateSubscription(storage, t)
+}
+
+func TestCreateSubscriptionAlreadyExists(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateSubscriptionAlreadyExists(storage, t)
+}
+
+func TestCreateSubscriptionBlogNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateSubscriptionBlogNotExist(storage, t)
+}
+
+func TestReadSubscriptions(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSubscriptions(storage, t)
+}
+
+func TestReadSubscriptionsUnread(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSubscriptionsUnread(storage, t)
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.DeleteSubscription(storage, t)
+}
+
+func TestReadSubscribedPostsAfter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadSubscribedPostsAfter(storage, t)
+}
@@ -0,0 +1,359 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateTag(ctx context.Context, tag *core.Tag) error {
+	// the tag and its assignments are written together (names that are
+	// already taken by another tag or alias are rejected by tag_name)
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		WITH inserted AS (
+			INSERT INTO tag
+				(name)
+			VALUES
+				($1)
+			RETURNING id
+		), aliases AS (
+			INSERT INTO tag_alias
+				(name, tag_id)
+			SELECT unnest($2::text[]), inserted.id
+			FROM inserted
+		)
+		SELECT id
+		FROM inserted`
+	aliases := tag.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	row := tx.QueryRow(ctx, stmt, tag.Name, aliases)
+
+	err = scan(row, &tag.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateTag(ctx, tag)
+		}
+		return err
+	}
+
+	err = tagPosts(ctx, tx, tag.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateTag(ctx, tag)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *storage) ReadTag(ctx context.Context, id int) (core.Tag, error) {
+	stmt := `
+		SELECT
+			tag.id,
+			tag.name,
+			array_remove(array_agg(tag_alias.name ORDER BY tag_alias.name), NULL) AS aliases,
+			(SELECT count(*) FROM post_tag WHERE post_tag.tag_id = tag.id) AS post_count
+		FROM tag
+		LEFT JOIN tag_alias
+			ON tag_alias.tag_id = tag.id
+		WHERE tag.id = $1
+		GROUP BY tag.id, tag.name`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var tag core.Tag
+	err := scan(
+		row,
+		&tag.ID,
+		&tag.Name,
+		&tag.Aliases,
+		&tag.PostCount,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadTag(ctx, id)
+		}
+		return core.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (s *storage) ReadTagByName(ctx context.Context, name string) (core.Tag, error) {
+	stmt := `
+		SELECT tag_id
+		FROM tag_term
+		WHERE lower(name) = lower($1)
+		LIMIT 1`
+	row := s.conn.QueryRow(ctx, stmt, name)
+
+	var id int
+	err := scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadTagByName(ctx, name)
+		}
+		return core.Tag{}, err
+	}
+
+	return s.ReadTag(ctx, id)
+}
+
+func (s *storage) ReadTags(ctx context.Context, limit, offset int) ([]core.Tag, error) {
+	stmt := `
+		SELECT
+			tag.id,
+			tag.name,
+			array_remove(array_agg(tag_alias.name ORDER BY tag_alias.name), NULL) AS aliases,
+			(SELECT count(*) FROM post_tag WHERE post_tag.tag_id = tag.id) AS post_count
+		FROM tag
+		LEFT JOIN tag_alias
+			ON tag_alias.tag_id = tag.id
+		GROUP BY tag.id, tag.name
+		ORDER BY lower(tag.name) ASC
+		LIMIT $1 OFFSET $2`
+	rows, err := s.conn.Query(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// use make here to JSON encode as an empty array instead of null
+	tags := make([]core.Tag, 0)
+	for rows.Next() {
+		var tag core.Tag
+		err := scan(
+			rows,
+			&tag.ID,
+			&tag.Name,
+			&tag.Aliases,
+			&tag.PostCount,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadTags(ctx, limit, offset)
+			}
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (s *storage) DeleteTag(ctx context.Context, id int) error {
+	// aliases and assignments are removed via ON DELETE CASCADE
+	stmt := `
+		DELETE FROM tag
+		WHERE id = $1
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteTag(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) CreateTagAlias(ctx context.Context, tagID int, alias string) error {
+	// the alias and its assignments are written together
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		INSERT INTO tag_alias
+			(name, tag_id)
+		SELECT $2, tag.id
+		FROM tag
+		WHERE tag.id = $1
+		RETURNING id`
+	row := tx.QueryRow(ctx, stmt, tagID, alias)
+
+	var id int
+	err = scan(row, &id)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateTagAlias(ctx, tagID, alias)
+		}
+		return err
+	}
+
+	err = tagPosts(ctx, tx, tagID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateTagAlias(ctx, tagID, alias)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *storage) DeleteTagAlias(ctx context.Context, tagID int, alias string) error {
+	// the alias and its assignments are removed together
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		DELETE FROM tag_alias
+		WHERE tag_id = $1
+			AND lower(name) = lower($2)
+		RETURNING id`
+	row := tx.QueryRow(ctx, stmt, tagID, alias)
+
+	var deleted int
+	err = scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteTagAlias(ctx, tagID, alias)
+		}
+		return err
+	}
+
+	err = tagPosts(ctx, tx, tagID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteTagAlias(ctx, tagID, alias)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *storage) RetagPosts(ctx context.Context) error {
+	stmt := `
+		WITH matches AS (
+			SELECT
+				post.id AS post_id,
+				tag_term.tag_id,
+				max(ts_rank_cd(post.content_index, phraseto_tsquery('english', tag_term.name))) AS rank
+			FROM post
+			INNER JOIN tag_term
+				ON phraseto_tsquery('english', tag_term.name) @@ post.content_index
+			GROUP BY post.id, tag_term.tag_id
+		), removed AS (
+			DELETE FROM post_tag
+			WHERE (post_id, tag_id) NOT IN (SELECT post_id, tag_id FROM matches)
+			RETURNING post_id
+		), assigned AS (
+			INSERT INTO post_tag
+				(post_id, tag_id, rank)
+			SELECT post_id, tag_id, rank
+			FROM matches
+			ON CONFLICT (post_id, tag_id) DO UPDATE
+				SET rank = EXCLUDED.rank
+			RETURNING post_id
+		)
+		SELECT
+			(SELECT count(*) FROM removed),
+			(SELECT count(*) FROM assigned)`
+	row := s.conn.QueryRow(ctx, stmt)
+
+	var removed, assigned int
+	err := scan(row, &removed, &assigned)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.RetagPosts(ctx)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// tagPost updates the tags assigned to a single post (and how relevant
+// each one is) to match its content.
+func tagPost(ctx context.Context, q querier, postID int) error {
+	stmt := `
+		WITH matches AS (
+			SELECT
+				tag_term.tag_id,
+				max(ts_rank_cd(post.content_index, phraseto_tsquery('english', tag_term.name))) AS rank
+			FROM post
+			INNER JOIN tag_term
+				ON phraseto_tsquery('english', tag_term.name) @@ post.content_index
+			WHERE post.id = $1
+			GROUP BY tag_term.tag_id
+		), removed AS (
+			DELETE FROM post_tag
+			WHERE post_id = $1
+				AND tag_id NOT IN (SELECT tag_id FROM matches)
+			RETURNING tag_id
+		), assigned AS (
+			INSERT INTO post_tag
+				(post_id, tag_id, rank)
+			SELECT $1, tag_id, rank
+			FROM matches
+			ON CONFLICT (post_id, tag_id) DO UPDATE
+				SET rank = EXCLUDED.rank
+			RETURNING tag_id
+		)
+		SELECT
+			(SELECT count(*) FROM removed),
+			(SELECT count(*) FROM assigned)`
+	row := q.QueryRow(ctx, stmt, postID)
+
+	// retries are left to the caller (which owns the transaction)
+	var removed, assigned int
+	return scan(row, &removed, &assigned)
+}
+
+// tagPosts updates which posts a single tag is assigned to after its
+// name or aliases change.
+func tagPosts(ctx context.Context, q querier, tagID int) error {
+	stmt := `
+		WITH matches AS (
+			SELECT
+				post.id AS post_id,
+				max(ts_rank_cd(post.content_index, phraseto_tsquery('english', tag_term.name))) AS rank
+			FROM post
+			INNER JOIN tag_term
+				ON phraseto_tsquery('english', tag_term.name) @@ post.content_index
+			WHERE tag_term.tag_id = $1
+			GROUP BY post.id
+		), removed AS (
+			DELETE FROM post_tag
+			WHERE tag_id = $1
+				AND post_id NOT IN (SELECT post_id FROM matches)
+			RETURNING post_id
+		), assigned AS (
+			INSERT INTO post_tag
+				(post_id, tag_id, rank)
+			SELECT post_id, $1, rank
+			FROM matches
+			ON CONFLICT (post_id, tag_id) DO UPDATE
+				SET rank = EXCLUDED.rank
+			RETURNING post_id
+		)
+		SELECT
+			(SELECT count(*) FROM removed),
+			(SELECT count(*) FROM assigned)`
+	row := q.QueryRow(ctx, stmt, tagID)
+
+	// retries are left to the caller (which owns the transaction)
+	var removed, assigned int
+	return scan(row, &removed, &assigned)
+}
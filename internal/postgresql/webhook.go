@@ -0,0 +1,287 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (s *storage) CreateWebhook(ctx context.Context, webhook *core.Webhook) error {
+	stmt := `
+		INSERT INTO webhook
+			(url, secret, tags, query)
+		VALUES
+			($1, $2, $3, $4)
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, webhook.URL, webhook.Secret, webhook.Tags, webhook.Query)
+
+	err := scan(row, &webhook.ID)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateWebhook(ctx, webhook)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadWebhook(ctx context.Context, id int) (core.Webhook, error) {
+	stmt := `
+		SELECT
+			id,
+			url,
+			secret,
+			tags,
+			query
+		FROM webhook
+		WHERE id = $1`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var webhook core.Webhook
+	err := scan(
+		row,
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Tags,
+		&webhook.Query,
+	)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadWebhook(ctx, id)
+		}
+		return core.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s *storage) ReadWebhooks(ctx context.Context, limit, offset int) ([]core.Webhook, error) {
+	stmt := `
+		SELECT
+			id,
+			url,
+			secret,
+			tags,
+			query
+		FROM webhook
+		ORDER BY id ASC
+		LIMIT $1 OFFSET $2`
+	rows, err := s.conn.Query(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]core.Webhook, 0)
+	for rows.Next() {
+		var webhook core.Webhook
+		err := scan(
+			rows,
+			&webhook.ID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.Tags,
+			&webhook.Query,
+		)
+		if err != nil {
+			if errors.Is(err, core.ErrRetry) {
+				return s.ReadWebhooks(ctx, limit, offset)
+			}
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (s *storage) DeleteWebhook(ctx context.Context, id int) error {
+	// deliveries are removed via ON DELETE CASCADE
+	stmt := `
+		DELETE FROM webhook
+		WHERE id = $1
+		RETURNING id`
+	row := s.conn.QueryRow(ctx, stmt, id)
+
+	var deleted int
+	err := scan(row, &deleted)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.DeleteWebhook(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) CreateWebhookDelivery(ctx context.Context, delivery *core.WebhookDelivery) error {
+	stmt := `
+		INSERT INTO webhook_delivery
+			(webhook_id, post_id, payload, status, attempts, next_attempt_at)
+		SELECT webhook.id, post.id, $3, $4, $5, $6
+		FROM webhook, post
+		WHERE webhook.id = $1
+			AND post.id = $2
+		RETURNING id, created_at`
+	args := []interface{}{
+		delivery.WebhookID,
+		delivery.PostID,
+		[]byte(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+	}
+	row := s.conn.QueryRow(ctx, stmt, args...)
+
+	err := scan(row, &delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.CreateWebhookDelivery(ctx, delivery)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *storage) ReadWebhookDeliveries(ctx context.Context, webhookID int, limit, offset int) ([]core.WebhookDelivery, error) {
+	stmt := `
+		SELECT
+			id,
+			webhook_id,
+			post_id,
+			payload,
+			status,
+			attempts,
+			next_attempt_at,
+			last_attempt_at,
+			http_status,
+			last_error,
+			created_at
+		FROM webhook_delivery
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := s.conn.Query(ctx, stmt, webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadWebhookDeliveries(ctx, webhookID, limit, offset)
+		}
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *storage) ReadDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]core.WebhookDelivery, error) {
+	stmt := `
+		SELECT
+			id,
+			webhook_id,
+			post_id,
+			payload,
+			status,
+			attempts,
+			next_attempt_at,
+			last_attempt_at,
+			http_status,
+			last_error,
+			created_at
+		FROM webhook_delivery
+		WHERE status = 'pending'
+			AND next_attempt_at <= $1
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT $2`
+	rows, err := s.conn.Query(ctx, stmt, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.ReadDueWebhookDeliveries(ctx, now, limit)
+		}
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *storage) UpdateWebhookDelivery(ctx context.Context, delivery core.WebhookDelivery) error {
+	stmt := `
+		UPDATE webhook_delivery
+		SET
+			status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_attempt_at = $5,
+			http_status = $6,
+			last_error = $7
+		WHERE id = $1
+		RETURNING id`
+	args := []interface{}{
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.HTTPStatus,
+		delivery.LastError,
+	}
+	row := s.conn.QueryRow(ctx, stmt, args...)
+
+	var updated int
+	err := scan(row, &updated)
+	if err != nil {
+		if errors.Is(err, core.ErrRetry) {
+			return s.UpdateWebhookDelivery(ctx, delivery)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func scanWebhookDeliveries(rows pgx.Rows) ([]core.WebhookDelivery, error) {
+	deliveries := make([]core.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery core.WebhookDelivery
+		err := scan(
+			rows,
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.PostID,
+			(*[]byte)(&delivery.Payload),
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastAttemptAt,
+			&delivery.HTTPStatus,
+			&delivery.LastError,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,72 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateWebhook(storage, t)
+}
+
+func TestReadWebhook(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadWebhook(storage, t)
+}
+
+func TestReadWebhooks(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadWebhooks(storage, t)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.DeleteWebhook(storage, t)
+}
+
+func TestCreateWebhookDelivery(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateWebhookDelivery(storage, t)
+}
+
+func TestCreateWebhookDeliveryNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.CreateWebhookDeliveryNotExist(storage, t)
+}
+
+func TestReadWebhookDeliveries(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.ReadWebhookDeliveries(storage, t)
+}
+
+func TestUpdateWebhookDelivery(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	test.UpdateWebhookDelivery(storage, t)
+}
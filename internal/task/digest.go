@@ -0,0 +1,181 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/mail"
+)
+
+var (
+	// most posts included in a single digest (the rest are sent next time)
+	digestLimit = 100
+	// posts first seen a little before the previous digest are considered
+	// again in case they were synced while it was being sent
+	digestOverlap = 1 * time.Hour
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+type sendDigestTask struct {
+	worker  *Worker
+	storage core.Storage
+	sender  mail.Sender
+	to      []string
+	search  core.SearchRequest
+	every   time.Duration
+}
+
+// SendDigest creates a task that emails the posts matching a search to a
+// list of recipients once every so often. Digests always cover the posts
+// found since the previous one (so date filters are ignored).
+func (w *Worker) SendDigest(storage core.Storage, sender mail.Sender, to []string, search core.SearchRequest, every time.Duration) Task {
+	search.Since = time.Time{}
+	search.Until = time.Time{}
+
+	task := sendDigestTask{
+		worker:  w,
+		storage: storage,
+		sender:  sender,
+		to:      to,
+		search:  search,
+		every:   every,
+	}
+	return &task
+}
+
+// Run checks whether a digest is due every interval (so that restarts
+// don't delay or repeat digests).
+func (t *sendDigestTask) Run(interval time.Duration) {
+	err := t.sendDigest(false)
+	if err != nil {
+		t.worker.logError(err)
+	}
+
+	c := time.Tick(interval)
+	for {
+		<-c
+
+		err := t.sendDigest(false)
+		if err != nil {
+			t.worker.logError(err)
+		}
+	}
+}
+
+// RunNow sends a digest right away (if there are any new posts).
+func (t *sendDigestTask) RunNow() error {
+	return t.sendDigest(true)
+}
+
+func (t *sendDigestTask) sendDigest(force bool) error {
+	t.worker.Add(1)
+	defer t.worker.Done()
+
+	now := time.Now()
+
+	// the first digest covers a single period
+	since := now.Add(-t.every)
+	window := since
+
+	last, err := t.storage.ReadLastDigest(context.Background())
+	switch {
+	case err == nil:
+		// a full digest may have left posts behind
+		backlog := false
+		if len(last.PostIDs) >= digestLimit {
+			left, err := t.storage.ReadUndigestedPosts(context.Background(), t.search, last.Since, 1)
+			if err != nil {
+				return err
+			}
+			backlog = len(left) > 0
+		}
+
+		// if so the next one is due right away and continues from the
+		// same window (otherwise digests go back to their usual schedule)
+		if backlog {
+			since = last.Since
+			window = last.Since
+		} else {
+			if !force && now.Sub(last.SentAt) < t.every {
+				return nil
+			}
+			since = last.SentAt
+			window = since.Add(-digestOverlap)
+		}
+	case errors.Is(err, core.ErrNotExist):
+	default:
+		return err
+	}
+
+	// posts that were already sent are skipped by storage
+	posts, err := t.storage.ReadUndigestedPosts(context.Background(), t.search, window, digestLimit)
+	if err != nil {
+		return err
+	}
+
+	// nothing new to send (check again next time)
+	if len(posts) == 0 {
+		return nil
+	}
+
+	subject, body, err := renderDigest(posts, since)
+	if err != nil {
+		return err
+	}
+
+	err = t.sender.Send(t.to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	// remember what was sent so that it isn't sent again
+	digest := core.NewDigest(window, now, posts)
+	err = t.storage.CreateDigest(context.Background(), &digest)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("sent digest of %d posts to %d recipients\n", len(posts), len(t.to))
+	t.worker.log(msg)
+
+	return nil
+}
+
+// renderDigest returns the subject and HTML body of a digest email.
+func renderDigest(posts []core.Post, since time.Time) (string, string, error) {
+	ts, err := template.ParseFS(templatesFS, "templates/digest.email.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := fmt.Sprintf("Bloggulus: %d new posts", len(posts))
+	if len(posts) == 1 {
+		subject = "Bloggulus: 1 new post"
+	}
+
+	data := struct {
+		Subject string
+		Since   time.Time
+		Posts   []core.Post
+	}{
+		Subject: subject,
+		Since:   since,
+		Posts:   posts,
+	}
+
+	var b bytes.Buffer
+	err = ts.Execute(&b, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return subject, b.String(), nil
+}
@@ -0,0 +1,233 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/mail"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestSendDigest(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock a blog with a couple posts
+	blog := test.CreateMockBlog(storage, t)
+	var posts []core.Post
+	for i := 0; i < 2; i++ {
+		post := test.NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, post)
+	}
+
+	// send digests through a local SMTP server
+	server := test.NewSMTPServer(t)
+	sender := mail.NewSender(server.Host, server.Port, "", "", "bloggulus@example.com")
+	logger := test.NewLogger()
+
+	// only include posts from the mock blog
+	to := []string{"team@example.com"}
+	search := core.ParseSearch(fmt.Sprintf("blog:%d", blog.ID), time.Now())
+
+	worker := NewWorker(logger)
+	sendDigest := worker.SendDigest(storage, sender, to, search, 7*24*time.Hour)
+	err := sendDigest.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(messages))
+	}
+
+	msg := messages[0]
+	if strings.Join(msg.To, ",") != strings.Join(to, ",") {
+		t.Fatalf("want %v, got %v", to, msg.To)
+	}
+	if msg.Subject != "Bloggulus: 2 new posts" {
+		t.Fatalf("want %v, got %v", "Bloggulus: 2 new posts", msg.Subject)
+	}
+	for _, post := range posts {
+		if !strings.Contains(msg.Body, post.Title) {
+			t.Fatalf("expected post %q in digest", post.Title)
+		}
+	}
+
+	// nothing new means nothing to send
+	err = sendDigest.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Messages()) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(server.Messages()))
+	}
+
+	// the next digest only has the new post
+	post := test.NewMockPost(blog)
+	err = storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sendDigest.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages = server.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(messages))
+	}
+
+	msg = messages[1]
+	if !strings.Contains(msg.Body, post.Title) {
+		t.Fatalf("expected post %q in digest", post.Title)
+	}
+	for _, post := range posts {
+		if strings.Contains(msg.Body, post.Title) {
+			t.Fatalf("unexpected repeat of post %q in digest", post.Title)
+		}
+	}
+}
+
+func TestSendDigestBacklog(t *testing.T) {
+	// shrink digests so that a few posts overflow one
+	limit := digestLimit
+	digestLimit = 2
+	defer func() {
+		digestLimit = limit
+	}()
+
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock a blog with more posts than fit in a digest
+	blog := test.CreateMockBlog(storage, t)
+	var posts []core.Post
+	for i := 0; i < 3; i++ {
+		post := test.NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, post)
+	}
+
+	server := test.NewSMTPServer(t)
+	sender := mail.NewSender(server.Host, server.Port, "", "", "bloggulus@example.com")
+	logger := test.NewLogger()
+
+	to := []string{"team@example.com"}
+	search := core.ParseSearch(fmt.Sprintf("blog:%d", blog.ID), time.Now())
+
+	worker := NewWorker(logger)
+	sendDigest := worker.SendDigest(storage, sender, to, search, 7*24*time.Hour)
+
+	// the first digest is full (and has the earliest posts)
+	err := sendDigest.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(messages))
+	}
+	for _, post := range posts[:2] {
+		if !strings.Contains(messages[0].Body, post.Title) {
+			t.Fatalf("expected post %q in digest", post.Title)
+		}
+	}
+
+	// the next one is due right away with the post left behind
+	err = sendDigest.(*sendDigestTask).sendDigest(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages = server.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(messages))
+	}
+	if !strings.Contains(messages[1].Body, posts[2].Title) {
+		t.Fatalf("expected post %q in digest", posts[2].Title)
+	}
+}
+
+func TestSendDigestBacklogSent(t *testing.T) {
+	// shrink digests so that a couple posts fill one
+	limit := digestLimit
+	digestLimit = 2
+	defer func() {
+		digestLimit = limit
+	}()
+
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock a blog with exactly as many posts as fit in a digest
+	blog := test.CreateMockBlog(storage, t)
+	for i := 0; i < 2; i++ {
+		post := test.NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := test.NewSMTPServer(t)
+	sender := mail.NewSender(server.Host, server.Port, "", "", "bloggulus@example.com")
+	logger := test.NewLogger()
+
+	to := []string{"team@example.com"}
+	search := core.ParseSearch(fmt.Sprintf("blog:%d", blog.ID), time.Now())
+
+	worker := NewWorker(logger)
+	sendDigest := worker.SendDigest(storage, sender, to, search, 7*24*time.Hour)
+
+	// the first digest is full but leaves nothing behind
+	err := sendDigest.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Messages()) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(server.Messages()))
+	}
+
+	// so a new post waits until the next digest is due
+	post := test.NewMockPost(blog)
+	err = storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sendDigest.(*sendDigestTask).sendDigest(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Messages()) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(server.Messages()))
+	}
+}
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"sort"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+var (
+	minSyncInterval = 15 * time.Minute
+	maxSyncInterval = 24 * time.Hour
+
+	// number of recent posts used to estimate publishing frequency
+	scheduleWindow = 10
+
+	minDeliveryBackoff = 1 * time.Minute
+	maxDeliveryBackoff = 6 * time.Hour
+)
+
+// syncInterval estimates how long to wait before syncing a blog again. Busy
+// blogs are polled more often while dormant ones back off. A refresh hint
+// from the feed (TTL) is respected as a lower bound.
+func syncInterval(posts []core.Post, hint time.Duration, now time.Time) time.Duration {
+	var times []time.Time
+	for _, post := range posts {
+		times = append(times, post.Updated)
+	}
+
+	// newest first
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].After(times[j])
+	})
+	if len(times) > scheduleWindow {
+		times = times[:scheduleWindow]
+	}
+
+	// without any posts there is nothing to go on
+	interval := maxSyncInterval
+	if len(times) > 0 {
+		// time since the most recent post
+		expected := now.Sub(times[0])
+
+		// average gap between recent posts (if longer)
+		if len(times) > 1 {
+			span := times[0].Sub(times[len(times)-1])
+			gap := span / time.Duration(len(times)-1)
+			if gap > expected {
+				expected = gap
+			}
+		}
+
+		// check a few times per expected post
+		interval = expected / 4
+	}
+
+	if interval < hint {
+		interval = hint
+	}
+	if interval < minSyncInterval {
+		interval = minSyncInterval
+	}
+	if interval > maxSyncInterval {
+		interval = maxSyncInterval
+	}
+
+	return interval
+}
+
+// deliveryBackoff determines how long to wait after a number of failed
+// attempts before trying again (doubling each time).
+func deliveryBackoff(attempts int) time.Duration {
+	backoff := minDeliveryBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxDeliveryBackoff {
+			return maxDeliveryBackoff
+		}
+	}
+	return backoff
+}
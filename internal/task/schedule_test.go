@@ -0,0 +1,65 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func TestSyncInterval(t *testing.T) {
+	now := time.Now()
+
+	// generate n posts spaced evenly apart (most recent at now - gap)
+	postsEvery := func(n int, gap time.Duration) []core.Post {
+		var posts []core.Post
+		for i := 1; i <= n; i++ {
+			post := core.Post{Updated: now.Add(-time.Duration(i) * gap)}
+			posts = append(posts, post)
+		}
+		return posts
+	}
+
+	tests := []struct {
+		name  string
+		posts []core.Post
+		hint  time.Duration
+		want  time.Duration
+	}{
+		{"no posts", nil, 0, maxSyncInterval},
+		{"busy", postsEvery(10, 2*time.Hour), 0, 30 * time.Minute},
+		{"very busy", postsEvery(10, 5*time.Minute), 0, minSyncInterval},
+		{"daily", postsEvery(10, 24*time.Hour), 0, 6 * time.Hour},
+		{"dormant", postsEvery(3, 365*24*time.Hour), 0, maxSyncInterval},
+		{"hint", postsEvery(10, 2*time.Hour), 2 * time.Hour, 2 * time.Hour},
+		{"huge hint", postsEvery(10, 2*time.Hour), 365 * 24 * time.Hour, maxSyncInterval},
+	}
+
+	for _, test := range tests {
+		got := syncInterval(test.posts, test.hint, now)
+		if got != test.want {
+			t.Errorf("%s: want %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, minDeliveryBackoff},
+		{2, 2 * minDeliveryBackoff},
+		{3, 4 * minDeliveryBackoff},
+		{9, 256 * minDeliveryBackoff},
+		{10, maxDeliveryBackoff},
+		{100, maxDeliveryBackoff},
+	}
+
+	for _, test := range tests {
+		got := deliveryBackoff(test.attempts)
+		if got != test.want {
+			t.Errorf("%d attempts: want %v, got %v", test.attempts, test.want, got)
+		}
+	}
+}
@@ -2,25 +2,35 @@ package task
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/theandrew168/bloggulus/internal/canonical"
 	"github.com/theandrew168/bloggulus/internal/core"
 	"github.com/theandrew168/bloggulus/internal/feed"
 )
 
 type syncBlogsTask struct {
-	worker  *Worker
-	storage core.Storage
-	reader  feed.Reader
+	worker      *Worker
+	storage     core.Storage
+	reader      feed.Reader
+	concurrency int
 }
 
-func (w *Worker) SyncBlogs(storage core.Storage, reader feed.Reader) Task {
+// SyncBlogs creates a task that syncs at most concurrency blogs at once.
+func (w *Worker) SyncBlogs(storage core.Storage, reader feed.Reader, concurrency int) Task {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
 	task := syncBlogsTask{
-		worker:  w,
-		storage: storage,
-		reader:  reader,
+		worker:      w,
+		storage:     storage,
+		reader:      reader,
+		concurrency: concurrency,
 	}
 	return &task
 }
@@ -51,99 +61,259 @@ func (t *syncBlogsTask) syncBlogs() error {
 	defer t.worker.Done()
 
 	limit := 50
-	offset := 0
+	afterID := 0
 
-	// read initial batch of blogs
-	blogs, err := t.storage.ReadBlogs(context.Background(), limit, offset)
+	// read initial batch of blogs that are due
+	blogs, err := t.storage.ReadBlogsDueForSync(context.Background(), afterID, limit)
 	if err != nil {
 		return err
 	}
 
-	// kick off blog syncs in batches
+	// start a fixed pool of sync workers
+	queue := make(chan core.Blog)
 	var wg sync.WaitGroup
+	for i := 0; i < t.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for blog := range queue {
+				t.syncBlog(blog)
+			}
+		}()
+	}
+
+	// feed the pool in batches
 	for len(blogs) > 0 {
-		// sync each blog in parallel
 		for _, blog := range blogs {
-			wg.Add(1)
-			go t.syncBlog(&wg, blog)
+			queue <- blog
 		}
 
 		// read the next batch
-		offset += limit
-		blogs, err = t.storage.ReadBlogs(context.Background(), limit, offset)
+		afterID = blogs[len(blogs)-1].ID
+		blogs, err = t.storage.ReadBlogsDueForSync(context.Background(), afterID, limit)
 		if err != nil {
-			wg.Wait()
-			return err
+			break
 		}
 	}
 
+	close(queue)
 	wg.Wait()
-	return nil
+	return err
 }
 
-func (t *syncBlogsTask) syncBlog(wg *sync.WaitGroup, blog core.Blog) {
-	defer wg.Done()
+func (t *syncBlogsTask) syncBlog(blog core.Blog) {
+	now := time.Now()
 
-	limit := 50
-	offset := 0
+	// only keep the feed's new cache validators if every post was synced
+	// (otherwise the next sync could skip the posts that failed)
+	etag, lastModified := blog.ETag, blog.LastModified
 
-	// build a set of known post URLs
-	knownPostURLs := make(map[string]bool)
+	created, updated, failed, err := t.syncBlogPosts(&blog)
+	if err != nil && !errors.Is(err, feed.ErrNotModified) {
+		t.worker.logError(err)
+	}
+	if err != nil || failed > 0 {
+		blog.ETag = etag
+		blog.LastModified = lastModified
+	}
 
-	// read initial batch of posts
-	knownPosts, err := t.storage.ReadPostsByBlog(context.Background(), blog.ID, limit, offset)
+	// record the outcome and decide when to check this blog next
+	t.recordStatus(blog, now, created, updated, err)
+	t.scheduleBlog(&blog)
+}
+
+// syncBlogPosts reads a blog's feed, creates any new posts and updates any
+// that have changed since they were last seen. The number of posts created,
+// updated and failed is returned along with any error that prevented syncing.
+func (t *syncBlogsTask) syncBlogPosts(blog *core.Blog) (int, int, int, error) {
+	// read posts from feed (skipping the rest if nothing changed)
+	feedPosts, err := t.reader.ReadBlogPosts(blog)
 	if err != nil {
-		t.worker.logError(err)
-		return
+		return 0, 0, 0, err
 	}
 
-	for len(knownPosts) > 0 {
-		// add each post URL to the set
-		for _, post := range knownPosts {
-			knownPostURLs[post.URL] = true
-		}
+	limit := 50
+	var cursor core.Cursor
 
-		// read the next batch
-		offset += limit
-		knownPosts, err = t.storage.ReadPostsByBlog(context.Background(), blog.ID, limit, offset)
+	// index known posts by GUID and by URL
+	knownByGUID := make(map[string]core.Post)
+	knownByURL := make(map[string]core.Post)
+
+	for {
+		// read the next batch of posts
+		posts, next, err := t.storage.ReadPostsByBlogAfter(context.Background(), blog.ID, cursor, limit)
 		if err != nil {
-			t.worker.logError(err)
-			return
+			return 0, 0, 0, err
 		}
-	}
 
-	// read posts from feed
-	feedPosts, err := t.reader.ReadBlogPosts(blog)
-	if err != nil {
-		t.worker.logError(err)
-		return
+		for _, post := range posts {
+			knownByGUID[post.GUID] = post
+			knownByURL[canonical.URL(post.URL)] = post
+		}
+
+		if next.IsZero() {
+			break
+		}
+		cursor = next
 	}
 
-	// newPosts = feedPosts - knownPosts
+	// compare the feed's posts against the known ones
 	var newPosts []core.Post
+	var changedPosts []core.Post
+	var guidPosts []core.Post
 	for _, post := range feedPosts {
-		if _, ok := knownPostURLs[post.URL]; ok {
+		// match on GUID first so that moved posts aren't duplicated
+		known, ok := knownByGUID[post.GUID]
+		if !ok {
+			known, ok = knownByURL[canonical.URL(post.URL)]
+		}
+		if !ok {
+			newPosts = append(newPosts, post)
+			continue
+		}
+
+		if postChanged(known, post) {
+			post.ID = known.ID
+			if post.Updated.IsZero() {
+				post.Updated = known.Updated
+			}
+			changedPosts = append(changedPosts, post)
 			continue
 		}
-		newPosts = append(newPosts, post)
+
+		// posts stored before GUIDs were read (their GUID is the URL) only
+		// need the real one saved: the content itself hasn't changed
+		if post.GUID != known.GUID {
+			post.ID = known.ID
+			guidPosts = append(guidPosts, post)
+		}
 	}
 
-	// attempt to read the content for each new post
-	for i, _ := range newPosts {
-		body, err := t.reader.ReadPostBody(newPosts[i])
+	// attempt to read the content for each new post (unless the feed had it)
+	for i := range newPosts {
+		if newPosts[i].BodySource == core.BodySourceFeed {
+			continue
+		}
+
+		err := t.reader.ReadPostBody(&newPosts[i])
 		if err != nil {
 			t.worker.logError(err)
 			continue
 		}
-		newPosts[i].Body = body
 	}
 
 	// sync each post with the database
+	failed := 0
+	var createdIDs []int
 	for _, post := range newPosts {
 		err = t.storage.CreatePost(context.Background(), &post)
 		if err != nil {
 			msg := fmt.Sprintf("sync %v %v\n", post.URL, err)
 			t.worker.log(msg)
+			failed++
+			continue
+		}
+		createdIDs = append(createdIDs, post.ID)
+	}
+	created := len(createdIDs)
+
+	// let webhooks know about the new posts
+	err = queueWebhooks(t.storage, createdIDs)
+	if err != nil {
+		t.worker.logError(err)
+	}
+
+	// refetch (unless the feed included it) and re-index each changed post
+	updated := 0
+	for _, post := range changedPosts {
+		// keep the stored version if the new content can't be read
+		if post.BodySource != core.BodySourceFeed {
+			err := t.reader.ReadPostBody(&post)
+			if err != nil {
+				t.worker.logError(err)
+				failed++
+				continue
+			}
+		}
+
+		err = t.storage.UpdatePost(context.Background(), post)
+		if err != nil {
+			msg := fmt.Sprintf("sync %v %v\n", post.URL, err)
+			t.worker.log(msg)
+			failed++
+			continue
 		}
+		updated++
+	}
+
+	// only the GUID is saved for these (they don't count as updated)
+	for _, post := range guidPosts {
+		err = t.storage.UpdatePostGUID(context.Background(), post.ID, post.GUID)
+		if err != nil {
+			msg := fmt.Sprintf("sync %v %v\n", post.URL, err)
+			t.worker.log(msg)
+			failed++
+		}
+	}
+
+	return created, updated, failed, nil
+}
+
+// postChanged reports whether a feed's version of a post differs from the
+// stored one. Timestamps are compared at the database's precision. Links
+// only count as changed when the post moved to another site since the
+// stored URL may have come from the page's own canonical link.
+func postChanged(known, post core.Post) bool {
+	if post.Title != known.Title {
+		return true
+	}
+
+	if !canonical.SameSite(post.URL, known.URL) {
+		return true
+	}
+
+	return post.Updated.Truncate(time.Microsecond).After(known.Updated)
+}
+
+func (t *syncBlogsTask) recordStatus(blog core.Blog, syncedAt time.Time, created, updated int, syncErr error) {
+	status := core.NewSyncStatus(blog.ID, syncedAt)
+	status.NewPosts = created
+	status.UpdatedPosts = updated
+
+	var statusErr *feed.StatusError
+	switch {
+	case syncErr == nil:
+		status.HTTPStatus = http.StatusOK
+	case errors.Is(syncErr, feed.ErrNotModified):
+		status.HTTPStatus = http.StatusNotModified
+	case errors.As(syncErr, &statusErr):
+		status.HTTPStatus = statusErr.StatusCode
+		status.LastError = syncErr.Error()
+	default:
+		status.LastError = syncErr.Error()
+	}
+
+	err := t.storage.RecordSyncStatus(context.Background(), &status)
+	if err != nil {
+		t.worker.logError(err)
+	}
+}
+
+func (t *syncBlogsTask) scheduleBlog(blog *core.Blog) {
+	// base the schedule on the most recent posts
+	posts, err := t.storage.ReadPostsByBlog(context.Background(), blog.ID, scheduleWindow, 0)
+	if err != nil {
+		t.worker.logError(err)
+		return
+	}
+
+	now := time.Now()
+	interval := syncInterval(posts, blog.TTL, now)
+	blog.NextSyncAt = now.Add(interval)
+
+	// also saves the feed's cache validators for the next sync
+	err = t.storage.UpdateBlogSchedule(context.Background(), *blog)
+	if err != nil {
+		t.worker.logError(err)
 	}
 }
@@ -3,6 +3,7 @@ package task_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/theandrew168/bloggulus/internal/core"
 	"github.com/theandrew168/bloggulus/internal/feed"
@@ -39,7 +40,7 @@ func TestSyncBlogs(t *testing.T) {
 
 	// run the sync blogs task
 	worker := task.NewWorker(logger)
-	syncBlogs := worker.SyncBlogs(storage, reader)
+	syncBlogs := worker.SyncBlogs(storage, reader, 4)
 	err = syncBlogs.RunNow()
 	if err != nil {
 		t.Fatal(err)
@@ -56,3 +57,360 @@ func TestSyncBlogs(t *testing.T) {
 		t.Fatalf("want %v, got %v\n", len(posts), len(synced))
 	}
 }
+
+func TestSyncBlogsUpdatesPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock some posts onto the blog
+	posts := []core.Post{
+		test.NewMockPost(blog),
+		test.NewMockPost(blog),
+	}
+	body := test.RandomString(256)
+
+	logger := test.NewLogger()
+	worker := task.NewWorker(logger)
+
+	// run an initial sync
+	reader := feed.NewMockReader(blog, posts, body)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate the author editing one of the posts
+	edited := posts[0]
+	edited.Title = test.RandomString(32)
+	edited.Updated = edited.Updated.Add(time.Hour)
+	posts[0] = edited
+
+	// make the blog due for another sync
+	blog, err = storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blog.NextSyncAt = time.Now().Add(-time.Minute)
+	err = storage.UpdateBlog(context.Background(), blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sync again with the edited feed
+	reader = feed.NewMockReader(blog, posts, body)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	synced, err := storage.ReadPostsByBlog(context.Background(), blog.ID, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ensure that the post was updated rather than duplicated
+	if len(synced) != len(posts) {
+		t.Fatalf("want %v, got %v\n", len(posts), len(synced))
+	}
+	if synced[0].Title != edited.Title {
+		t.Fatalf("want %v, got %v\n", edited.Title, synced[0].Title)
+	}
+
+	status, err := storage.ReadSyncStatus(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.NewPosts != 0 {
+		t.Fatalf("want %v, got %v\n", 0, status.NewPosts)
+	}
+	if status.UpdatedPosts != 1 {
+		t.Fatalf("want %v, got %v\n", 1, status.UpdatedPosts)
+	}
+}
+
+func TestSyncBlogsMovedPosts(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock some posts onto the blog
+	posts := []core.Post{
+		test.NewMockPost(blog),
+		test.NewMockPost(blog),
+	}
+	body := test.RandomString(256)
+
+	logger := test.NewLogger()
+	worker := task.NewWorker(logger)
+
+	// run an initial sync
+	reader := feed.NewMockReader(blog, posts, body)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate the blog moving to a new domain (GUIDs stay the same)
+	for i := range posts {
+		posts[i].URL = test.RandomURL(32)
+	}
+
+	// make the blog due for another sync
+	blog, err = storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blog.NextSyncAt = time.Now().Add(-time.Minute)
+	err = storage.UpdateBlog(context.Background(), blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sync again with the moved feed
+	reader = feed.NewMockReader(blog, posts, body)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	synced, err := storage.ReadPostsByBlog(context.Background(), blog.ID, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ensure that the posts were moved rather than duplicated
+	if len(synced) != len(posts) {
+		t.Fatalf("want %v, got %v\n", len(posts), len(synced))
+	}
+
+	urls := make(map[string]bool)
+	for _, post := range posts {
+		urls[post.URL] = true
+	}
+	for _, post := range synced {
+		if !urls[post.URL] {
+			t.Fatalf("post %v still has its old URL %v\n", post.ID, post.URL)
+		}
+	}
+}
+
+func TestSyncBlogsGUIDOnly(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate posts stored before GUIDs were read (GUID was the URL)
+	posts := []core.Post{
+		test.NewMockPost(blog),
+		test.NewMockPost(blog),
+	}
+	for _, post := range posts {
+		post.GUID = post.URL
+		err = storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// sync a feed that has the real GUIDs
+	logger := test.NewLogger()
+	worker := task.NewWorker(logger)
+
+	reader := feed.NewMockReader(blog, posts, test.RandomString(256))
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	synced, err := storage.ReadPostsByBlog(context.Background(), blog.ID, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ensure that the GUIDs were saved without duplicating the posts
+	if len(synced) != len(posts) {
+		t.Fatalf("want %v, got %v\n", len(posts), len(synced))
+	}
+
+	guids := make(map[string]bool)
+	for _, post := range posts {
+		guids[post.GUID] = true
+	}
+	for _, post := range synced {
+		if !guids[post.GUID] {
+			t.Fatalf("post %v still has its old GUID %v\n", post.ID, post.GUID)
+		}
+	}
+
+	// nothing about the posts themselves changed
+	status, err := storage.ReadSyncStatus(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.NewPosts != 0 {
+		t.Fatalf("want %v, got %v\n", 0, status.NewPosts)
+	}
+	if status.UpdatedPosts != 0 {
+		t.Fatalf("want %v, got %v\n", 0, status.UpdatedPosts)
+	}
+}
+
+func TestSyncBlogsFeedContent(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock a post whose full content is included in the feed
+	post := test.NewMockPost(blog)
+	post.Body = "syndicated"
+	post.BodySource = core.BodySourceFeed
+
+	// the page's content should never be fetched
+	posts := []core.Post{post}
+	body := "scraped"
+
+	reader := feed.NewMockReader(blog, posts, body)
+	logger := test.NewLogger()
+
+	worker := task.NewWorker(logger)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ensure that the feed's content was indexed
+	synced, err := storage.SearchPosts(context.Background(), post.Body, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsBlog(synced, blog) {
+		t.Fatalf("post was not indexed with the feed's content")
+	}
+
+	// and that the page's content was not
+	scraped, err := storage.SearchPosts(context.Background(), body, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containsBlog(scraped, blog) {
+		t.Fatalf("post was indexed with the page's content")
+	}
+}
+
+func containsBlog(posts []core.Post, blog core.Blog) bool {
+	for _, post := range posts {
+		if post.Blog.ID == blog.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSyncBlogsKeepsValidatorsOnFailure(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog that was fetched before
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blog.ETag = test.RandomString(32)
+	err = storage.UpdateBlog(context.Background(), blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the feed now has new validators and a post that can't be created
+	// (its URL already belongs to another blog's post)
+	taken := test.CreateMockPost(storage, t)
+	post := test.NewMockPost(blog)
+	post.URL = taken.URL
+
+	fetched := blog
+	fetched.ETag = test.RandomString(32)
+
+	logger := test.NewLogger()
+	worker := task.NewWorker(logger)
+
+	reader := feed.NewMockReader(fetched, []core.Post{post}, test.RandomString(256))
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the old validators are kept so that the feed is read again
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ETag != blog.ETag {
+		t.Fatalf("want %v, got %v\n", blog.ETag, got.ETag)
+	}
+
+	// make the blog due for another sync
+	got.NextSyncAt = time.Now().Add(-time.Minute)
+	err = storage.UpdateBlog(context.Background(), got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// once every post syncs the new validators are saved
+	post.URL = test.RandomURL(32)
+	reader = feed.NewMockReader(fetched, []core.Post{post}, test.RandomString(256))
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ETag != fetched.ETag {
+		t.Fatalf("want %v, got %v\n", fetched.ETag, got.ETag)
+	}
+}
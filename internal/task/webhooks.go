@@ -0,0 +1,236 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+// the only event sent to webhooks (so far)
+const webhookEventPostCreated = "post.created"
+
+var (
+	// failed deliveries are retried (see deliveryBackoff) until they run
+	// out of attempts (about a day after the first one)
+	maxDeliveryAttempts = 12
+
+	// number of webhooks or deliveries read at once
+	webhookBatchSize = 50
+
+	webhookUserAgent = "Bloggulus/1.0"
+	webhookTimeout   = 10 * time.Second
+)
+
+// webhookPayload is the JSON body of each delivery.
+type webhookPayload struct {
+	Event string    `json:"event"`
+	Post  core.Post `json:"post"`
+}
+
+// queueWebhooks queues a delivery of each new post to every webhook that
+// it matches. Deliveries are sent separately by the deliverWebhooksTask.
+func queueWebhooks(storage core.Storage, postIDs []int) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	// each post has the same payload for every webhook
+	payloads := make(map[int][]byte)
+
+	offset := 0
+	for {
+		webhooks, err := storage.ReadWebhooks(context.Background(), webhookBatchSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			matched, err := storage.MatchPosts(context.Background(), webhook.Search(), postIDs)
+			if err != nil {
+				return err
+			}
+
+			for _, postID := range matched {
+				payload, ok := payloads[postID]
+				if !ok {
+					payload, err = newWebhookPayload(storage, postID)
+					if err != nil {
+						return err
+					}
+					payloads[postID] = payload
+				}
+
+				delivery := core.NewWebhookDelivery(webhook.ID, postID, payload)
+				err = storage.CreateWebhookDelivery(context.Background(), &delivery)
+				if err != nil && !errors.Is(err, core.ErrExist) {
+					return err
+				}
+			}
+		}
+
+		if len(webhooks) < webhookBatchSize {
+			return nil
+		}
+		offset += webhookBatchSize
+	}
+}
+
+func newWebhookPayload(storage core.Storage, postID int) ([]byte, error) {
+	// read the post back to include its tags
+	post, err := storage.ReadPost(context.Background(), postID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := webhookPayload{
+		Event: webhookEventPostCreated,
+		Post:  post,
+	}
+	return json.Marshal(payload)
+}
+
+type deliverWebhooksTask struct {
+	worker  *Worker
+	storage core.Storage
+	client  *http.Client
+}
+
+// DeliverWebhooks creates a task that sends queued webhook deliveries.
+func (w *Worker) DeliverWebhooks(storage core.Storage) Task {
+	task := deliverWebhooksTask{
+		worker:  w,
+		storage: storage,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+	return &task
+}
+
+func (t *deliverWebhooksTask) Run(interval time.Duration) {
+	err := t.RunNow()
+	if err != nil {
+		t.worker.logError(err)
+	}
+
+	c := time.Tick(interval)
+	for {
+		<-c
+
+		err := t.deliverWebhooks()
+		if err != nil {
+			t.worker.logError(err)
+		}
+	}
+}
+
+func (t *deliverWebhooksTask) RunNow() error {
+	return t.deliverWebhooks()
+}
+
+func (t *deliverWebhooksTask) deliverWebhooks() error {
+	t.worker.Add(1)
+	defer t.worker.Done()
+
+	webhooks := make(map[int]core.Webhook)
+	for {
+		// every attempt pushes a delivery out of the due set
+		deliveries, err := t.storage.ReadDueWebhookDeliveries(context.Background(), time.Now(), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				webhook, err = t.storage.ReadWebhook(context.Background(), delivery.WebhookID)
+				if err != nil {
+					// deleted webhooks take their deliveries with them
+					if errors.Is(err, core.ErrNotExist) {
+						continue
+					}
+					return err
+				}
+				webhooks[webhook.ID] = webhook
+			}
+
+			t.deliver(webhook, &delivery)
+
+			err = t.storage.UpdateWebhookDelivery(context.Background(), delivery)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver attempts to send a delivery and records the outcome (scheduling
+// another attempt if it failed).
+func (t *deliverWebhooksTask) deliver(webhook core.Webhook, delivery *core.WebhookDelivery) {
+	now := time.Now()
+	status, err := t.send(webhook, *delivery)
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.HTTPStatus = status
+
+	if err == nil {
+		delivery.Status = core.DeliveryDelivered
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= maxDeliveryAttempts {
+		delivery.Status = core.DeliveryFailed
+
+		msg := fmt.Sprintf("webhook %d gave up on post %d: %v\n", webhook.ID, delivery.PostID, err)
+		t.worker.log(msg)
+		return
+	}
+
+	delivery.NextAttemptAt = now.Add(deliveryBackoff(delivery.Attempts))
+}
+
+// send POSTs a delivery's payload to its webhook along with a signature
+// of the payload. The response's status code is returned (zero if there
+// was no response).
+func (t *deliverWebhooksTask) send(webhook core.Webhook, delivery core.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-Bloggulus-Event", webhookEventPostCreated)
+	req.Header.Set("X-Bloggulus-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set("X-Bloggulus-Signature", webhook.Sign(delivery.Payload))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain (some of) the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%v: %d %s", webhook.URL, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,300 @@
+package task_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/task"
+	"github.com/theandrew168/bloggulus/internal/test"
+)
+
+func TestSyncBlogsQueuesWebhooks(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// subscribe a webhook to the blog's posts
+	webhook := test.NewMockWebhook()
+	webhook.Tags = []string{}
+	webhook.Query = fmt.Sprintf("blog:%d", blog.ID)
+	err = storage.CreateWebhook(context.Background(), &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock some posts onto the blog
+	posts := []core.Post{
+		test.NewMockPost(blog),
+		test.NewMockPost(blog),
+	}
+	reader := feed.NewMockReader(blog, posts, test.RandomString(256))
+	logger := test.NewLogger()
+
+	// run the sync blogs task
+	worker := task.NewWorker(logger)
+	syncBlogs := worker.SyncBlogs(storage, reader, 4)
+	err = syncBlogs.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each new post should be queued for the webhook
+	deliveries, err := storage.ReadWebhookDeliveries(context.Background(), webhook.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != len(posts) {
+		t.Fatalf("want %v, got %v", len(posts), len(deliveries))
+	}
+
+	for _, delivery := range deliveries {
+		if delivery.Status != core.DeliveryPending {
+			t.Fatalf("want %v, got %v", core.DeliveryPending, delivery.Status)
+		}
+
+		var payload struct {
+			Event string    `json:"event"`
+			Post  core.Post `json:"post"`
+		}
+		err = json.Unmarshal(delivery.Payload, &payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if payload.Event != "post.created" {
+			t.Fatalf("want %v, got %v", "post.created", payload.Event)
+		}
+		if payload.Post.ID != delivery.PostID {
+			t.Fatalf("want %v, got %v", delivery.PostID, payload.Post.ID)
+		}
+	}
+}
+
+func TestSyncBlogsDeliversWebhooks(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// a receiver that records every delivery
+	var mu sync.Mutex
+	var signatures []string
+	var bodies [][]byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get("X-Bloggulus-Signature"))
+	}))
+	defer ts.Close()
+
+	// mock and create a blog
+	blog := test.NewMockBlog()
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// subscribe the receiver to the blog's posts
+	webhook := core.NewWebhook(ts.URL, test.RandomString(32), nil, fmt.Sprintf("blog:%d", blog.ID))
+	err = storage.CreateWebhook(context.Background(), &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock a post (with all of its metadata) onto the blog
+	post := test.NewMockPost(blog)
+	published := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	post.Published = &published
+	reader := feed.NewMockReader(blog, []core.Post{post}, test.RandomString(256))
+	logger := test.NewLogger()
+
+	// sync the blog (queueing the post) and then deliver it
+	worker := task.NewWorker(logger)
+	err = worker.SyncBlogs(storage, reader, 4).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = worker.DeliverWebhooks(storage).RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := readDelivery(storage, t, webhook.ID)
+	if delivery.Status != core.DeliveryDelivered {
+		t.Fatalf("want %v, got %+v", core.DeliveryDelivered, delivery)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(bodies) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(bodies))
+	}
+
+	// the receiver can verify the payload with the secret
+	if signatures[0] != webhook.Sign(bodies[0]) {
+		t.Fatalf("want %v, got %v", webhook.Sign(bodies[0]), signatures[0])
+	}
+
+	var payload struct {
+		Event string    `json:"event"`
+		Post  core.Post `json:"post"`
+	}
+	err = json.Unmarshal(bodies[0], &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := payload.Post
+	if got.ID != delivery.PostID {
+		t.Fatalf("want %v, got %v", delivery.PostID, got.ID)
+	}
+	if got.URL != post.URL || got.Title != post.Title || got.Author != post.Author {
+		t.Fatalf("want %+v, got %+v", post, got)
+	}
+	if got.Published == nil || !got.Published.Equal(published) {
+		t.Fatalf("want %v, got %v", published, got.Published)
+	}
+	if got.FirstSeen.IsZero() {
+		t.Fatalf("want first seen, got %v", got.FirstSeen)
+	}
+	if got.Blog.ID != blog.ID {
+		t.Fatalf("want %v, got %v", blog.ID, got.Blog.ID)
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	// instantiate storage interface
+	storage := postgresql.NewStorage(conn)
+
+	// a receiver that fails until told otherwise
+	var mu sync.Mutex
+	fail := true
+	var signatures []string
+	var bodies [][]byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get("X-Bloggulus-Signature"))
+	}))
+	defer ts.Close()
+
+	webhook := core.NewWebhook(ts.URL, test.RandomString(32), nil, "")
+	err := storage.CreateWebhook(context.Background(), &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := test.CreateMockPost(storage, t)
+	payload := []byte(fmt.Sprintf(`{"event": "post.created", "post": {"id": %d}}`, post.ID))
+	delivery := core.NewWebhookDelivery(webhook.ID, post.ID, payload)
+	err = storage.CreateWebhookDelivery(context.Background(), &delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := test.NewLogger()
+	worker := task.NewWorker(logger)
+	deliverWebhooks := worker.DeliverWebhooks(storage)
+
+	// the first attempt fails and is retried later
+	err = deliverWebhooks.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := readDelivery(storage, t, webhook.ID)
+	if got.Status != core.DeliveryPending || got.Attempts != 1 || got.HTTPStatus != 500 {
+		t.Fatalf("want a failed attempt, got %+v", got)
+	}
+	if !got.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("want retry in the future, got %v", got.NextAttemptAt)
+	}
+
+	// make the retry due right away
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+
+	got.NextAttemptAt = time.Now()
+	err = storage.UpdateWebhookDelivery(context.Background(), got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = deliverWebhooks.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = readDelivery(storage, t, webhook.ID)
+	if got.Status != core.DeliveryDelivered || got.Attempts != 2 || got.HTTPStatus != 200 {
+		t.Fatalf("want a successful attempt, got %+v", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(bodies) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(bodies))
+	}
+	if string(bodies[0]) != string(got.Payload) {
+		t.Fatalf("want %s, got %s", got.Payload, bodies[0])
+	}
+
+	// the receiver can verify the payload with the secret
+	if signatures[0] != webhook.Sign(bodies[0]) {
+		t.Fatalf("want %v, got %v", webhook.Sign(bodies[0]), signatures[0])
+	}
+}
+
+func readDelivery(storage core.Storage, t *testing.T, webhookID int) core.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := storage.ReadWebhookDeliveries(context.Background(), webhookID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(deliveries))
+	}
+
+	return deliveries[0]
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func CreateAccount(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	// account should have an ID after creation
+	if account.ID == 0 {
+		t.Fatal("account id after creation should be nonzero")
+	}
+}
+
+func CreateAccountAlreadyExists(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	// usernames are unique regardless of case
+	duplicate := account
+	duplicate.Username = strings.ToUpper(account.Username)
+
+	err := storage.CreateAccount(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate account should return an error")
+	}
+}
+
+func ReadAccount(storage core.Storage, t *testing.T) {
+	account, password := CreateMockAccount(storage, t)
+
+	got, err := storage.ReadAccount(context.Background(), account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Username != account.Username {
+		t.Fatalf("want %v, got %v", account.Username, got.Username)
+	}
+
+	// the password hash should be read back as well
+	ok, err := got.PasswordMatches(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("password should match after reading the account")
+	}
+}
+
+func ReadAccountByUsername(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	got, err := storage.ReadAccountByUsername(context.Background(), strings.ToLower(account.Username))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != account.ID {
+		t.Fatalf("want %v, got %v", account.ID, got.ID)
+	}
+
+	_, err = storage.ReadAccountByUsername(context.Background(), RandomString(32))
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("reading a missing account should return an error")
+	}
+}
+
+func ReadAccountBySession(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	token := CreateMockSession(storage, t, account)
+
+	got, err := storage.ReadAccountBySession(context.Background(), core.HashSessionToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != account.ID {
+		t.Fatalf("want %v, got %v", account.ID, got.ID)
+	}
+}
+
+func ReadAccountBySessionExpired(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	session, token, err := core.NewSession(account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Expiry = time.Now().Add(-time.Minute)
+
+	err = storage.CreateSession(context.Background(), &session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expired sessions don't log anyone in
+	_, err = storage.ReadAccountBySession(context.Background(), core.HashSessionToken(token))
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("expired session should return an error")
+	}
+}
+
+func DeleteSession(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	token := CreateMockSession(storage, t, account)
+	hash := core.HashSessionToken(token)
+
+	err := storage.DeleteSession(context.Background(), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the session should no longer log the account in
+	_, err = storage.ReadAccountBySession(context.Background(), hash)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted session should return an error")
+	}
+
+	// and can't be deleted twice
+	err = storage.DeleteSession(context.Background(), hash)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleting a missing session should return an error")
+	}
+}
+
+// CreateMockAccount creates an account and also returns its password.
+func CreateMockAccount(storage core.Storage, t *testing.T) (core.Account, string) {
+	t.Helper()
+
+	// generate some random account data
+	password := RandomString(32)
+	account, err := core.NewAccount(RandomString(32), password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create an example account
+	err = storage.CreateAccount(context.Background(), &account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return account, password
+}
+
+// CreateMockSession logs an account in and returns the session's token.
+func CreateMockSession(storage core.Storage, t *testing.T, account core.Account) string {
+	t.Helper()
+
+	session, token, err := core.NewSession(account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.CreateSession(context.Background(), &session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
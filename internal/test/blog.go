@@ -3,7 +3,9 @@ package test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/theandrew168/bloggulus/internal/core"
 )
@@ -27,6 +29,36 @@ func CreateBlogAlreadyExists(storage core.Storage, t *testing.T) {
 	}
 }
 
+func CreateBlogCanonical(storage core.Storage, t *testing.T) {
+	// feeds are kept as given (the site may not support https)
+	blog := NewMockBlog()
+	feedURL := strings.Replace(blog.FeedURL, "https://", "http://", 1)
+	blog.FeedURL = feedURL
+
+	err := storage.CreateBlog(context.Background(), &blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FeedURL != feedURL {
+		t.Fatalf("want %v, got %v", feedURL, got.FeedURL)
+	}
+
+	// but the same feed linked in a slightly different way is a duplicate
+	duplicate := blog
+	duplicate.FeedURL = strings.Replace(feedURL, "http://", "HTTPS://", 1) + "?utm_source=rss"
+
+	err = storage.CreateBlog(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate blog should return an error")
+	}
+}
+
 func ReadBlog(storage core.Storage, t *testing.T) {
 	blog := CreateMockBlog(storage, t)
 
@@ -59,6 +91,213 @@ func ReadBlogs(storage core.Storage, t *testing.T) {
 	}
 }
 
+func ReadBlogStats(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+	empty := CreateMockBlog(storage, t)
+
+	updated := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	for i := 0; i < 2; i++ {
+		post := core.NewPost(RandomURL(32), RandomString(32), updated.Add(time.Duration(-i)*time.Hour), blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// page through every blog to find the ones created above
+	found := make(map[int]core.BlogStats)
+	limit := 100
+	for offset := 0; ; offset += limit {
+		stats, err := storage.ReadBlogStats(context.Background(), limit, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, stat := range stats {
+			if stat.ID == blog.ID || stat.ID == empty.ID {
+				found[stat.ID] = stat
+			}
+		}
+
+		if len(stats) < limit {
+			break
+		}
+	}
+
+	got, ok := found[blog.ID]
+	if !ok {
+		t.Fatalf("blog %v is missing", blog.ID)
+	}
+	if got.PostCount != 2 {
+		t.Fatalf("want %v, got %v", 2, got.PostCount)
+	}
+	if got.LastPost == nil || !got.LastPost.Equal(updated) {
+		t.Fatalf("want %v, got %v", updated, got.LastPost)
+	}
+
+	got, ok = found[empty.ID]
+	if !ok {
+		t.Fatalf("blog %v is missing", empty.ID)
+	}
+	if got.PostCount != 0 || got.LastPost != nil {
+		t.Fatalf("want no posts, got %v (last %v)", got.PostCount, got.LastPost)
+	}
+}
+
+func UpdateBlog(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// simulate the cache headers returned by a feed fetch
+	blog.ETag = RandomString(32)
+	blog.LastModified = RandomString(32)
+
+	err := storage.UpdateBlog(context.Background(), blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ETag != blog.ETag {
+		t.Fatalf("want %v, got %v", blog.ETag, got.ETag)
+	}
+	if got.LastModified != blog.LastModified {
+		t.Fatalf("want %v, got %v", blog.LastModified, got.LastModified)
+	}
+}
+
+func UpdateBlogNotExist(storage core.Storage, t *testing.T) {
+	blog := NewMockBlog()
+	blog.ID = 999999999
+
+	err := storage.UpdateBlog(context.Background(), blog)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("updating a missing blog should return an error")
+	}
+}
+
+func UpdateBlogSchedule(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// simulate an admin renaming the blog during a sync
+	renamed := blog
+	renamed.Title = RandomString(32)
+	err := storage.UpdateBlog(context.Background(), renamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sync only knows about the old title
+	blog.ETag = RandomString(32)
+	blog.LastModified = RandomString(32)
+	blog.NextSyncAt = time.Now().Add(time.Hour).Truncate(time.Second)
+	err = storage.UpdateBlogSchedule(context.Background(), blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadBlog(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != renamed.Title {
+		t.Fatalf("want %v, got %v", renamed.Title, got.Title)
+	}
+	if got.ETag != blog.ETag {
+		t.Fatalf("want %v, got %v", blog.ETag, got.ETag)
+	}
+	if got.LastModified != blog.LastModified {
+		t.Fatalf("want %v, got %v", blog.LastModified, got.LastModified)
+	}
+	if !got.NextSyncAt.Equal(blog.NextSyncAt) {
+		t.Fatalf("want %v, got %v", blog.NextSyncAt, got.NextSyncAt)
+	}
+}
+
+func ReadFailingBlogs(storage core.Storage, t *testing.T) {
+	failing := CreateMockFailingBlog(storage, t)
+	healthy := CreateMockBlog(storage, t)
+
+	blogs, err := storage.ReadFailingBlogs(context.Background(), 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, blog := range blogs {
+		if blog.ID == healthy.ID {
+			t.Fatalf("expected blog %v to not be failing", healthy.ID)
+		}
+		if blog.ID == failing.ID {
+			found = true
+		}
+	}
+
+	// test blogs have random titles so paging may hide this one
+	if !found && len(blogs) < 50 {
+		t.Fatalf("expected blog %v to be failing", failing.ID)
+	}
+}
+
+func ReadBlogsDueForSync(storage core.Storage, t *testing.T) {
+	// new blogs are due immediately
+	due := CreateMockBlog(storage, t)
+
+	// push this one into the future
+	later := CreateMockBlog(storage, t)
+	later.NextSyncAt = time.Now().Add(time.Hour)
+	err := storage.UpdateBlog(context.Background(), later)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blogs, err := storage.ReadBlogsDueForSync(context.Background(), due.ID-1, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blogs) < 1 || blogs[0].ID != due.ID {
+		t.Fatalf("expected blog %v to be due", due.ID)
+	}
+
+	for _, blog := range blogs {
+		if blog.ID == later.ID {
+			t.Fatalf("expected blog %v to not be due", later.ID)
+		}
+	}
+}
+
+func DeleteBlog(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	err := storage.DeleteBlog(context.Background(), post.Blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storage.ReadBlog(context.Background(), post.Blog.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted blog should not exist")
+	}
+
+	// posts should be deleted along with their blog
+	_, err = storage.ReadPost(context.Background(), post.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("posts of a deleted blog should not exist")
+	}
+}
+
+func DeleteBlogNotExist(storage core.Storage, t *testing.T) {
+	err := storage.DeleteBlog(context.Background(), 999999999)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleting a missing blog should return an error")
+	}
+}
+
 func CreateMockBlog(storage core.Storage, t *testing.T) core.Blog {
 	t.Helper()
 
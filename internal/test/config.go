@@ -7,7 +7,7 @@ import (
 	"github.com/theandrew168/bloggulus/internal/config"
 )
 
-func Config(t *testing.T) config.Config {
+func Config(t testing.TB) config.Config {
 	t.Helper()
 
 	// read the local development config file
@@ -20,5 +20,16 @@ func NewMockPost(blog core.Blog) core.Post {
 		RandomTime(),
 		blog,
 	)
+	post.GUID = "urn:uuid:" + RandomString(32)
+	post.Summary = RandomString(64)
+	post.Author = RandomString(16)
 	return post
 }
+
+func NewMockTag() core.Tag {
+	tag := core.NewTag(
+		RandomWord(16),
+		RandomWord(16),
+	)
+	return tag
+}
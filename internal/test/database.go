@@ -7,7 +7,7 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-func ConnectDB(t *testing.T) *pgxpool.Pool {
+func ConnectDB(t testing.TB) *pgxpool.Pool {
 	t.Helper()
 
 	cfg := Config(t)
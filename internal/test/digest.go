@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func CreateDigest(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	digest := core.NewDigest(time.Now().Add(-time.Hour), time.Now(), []core.Post{post})
+	err := storage.CreateDigest(context.Background(), &digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if digest.ID == 0 {
+		t.Fatal("digest ID should be set after creation")
+	}
+}
+
+func ReadLastDigest(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	digest := core.NewDigest(time.Now().Add(-time.Hour), time.Now(), []core.Post{post})
+	err := storage.CreateDigest(context.Background(), &digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadLastDigest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other tests may have sent a digest since this one
+	if got.SentAt.Before(digest.SentAt.Truncate(time.Microsecond)) {
+		t.Fatalf("want at least %v, got %v", digest.SentAt, got.SentAt)
+	}
+	if got.ID == digest.ID {
+		if len(got.PostIDs) != 1 || got.PostIDs[0] != post.ID {
+			t.Fatalf("want %v, got %v", digest.PostIDs, got.PostIDs)
+		}
+		if !got.Since.Equal(digest.Since.Truncate(time.Microsecond)) {
+			t.Fatalf("want %v, got %v", digest.Since, got.Since)
+		}
+	}
+}
+
+func ReadUndigestedPosts(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	var posts []core.Post
+	for i := 0; i < 2; i++ {
+		post := NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, post)
+	}
+
+	search := core.SearchRequest{BlogIDs: []int{blog.ID}}
+	since := time.Now().Add(-time.Hour)
+
+	got, err := storage.ReadUndigestedPosts(context.Background(), search, since, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(got))
+	}
+
+	// limited reads start with the earliest posts
+	got, err = storage.ReadUndigestedPosts(context.Background(), search, since, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].ID != posts[0].ID {
+		t.Fatalf("want %v, got %v", posts[:1], got)
+	}
+
+	// send the first post in a digest
+	digest := core.NewDigest(time.Now().Add(-time.Hour), time.Now(), posts[:1])
+	err = storage.CreateDigest(context.Background(), &digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = storage.ReadUndigestedPosts(context.Background(), search, since, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].ID != posts[1].ID {
+		t.Fatalf("want %v, got %v", posts[1:], got)
+	}
+
+	// posts first seen before since are left out
+	got, err = storage.ReadUndigestedPosts(context.Background(), search, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 0 {
+		t.Fatalf("want %v, got %v", 0, len(got))
+	}
+}
@@ -0,0 +1,256 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func MarkPostRead(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	post := CreateMockPost(storage, t)
+
+	err := storage.MarkPostRead(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reading a post again is fine
+	err = storage.MarkPostRead(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []core.Post{post}
+	err = storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !posts[0].Read {
+		t.Fatal("post should be read after marking it")
+	}
+}
+
+func MarkPostReadNotExist(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	err := storage.MarkPostRead(context.Background(), account.ID, 999999999)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("marking a missing post should return an error")
+	}
+}
+
+func SavePost(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	post := CreateMockPost(storage, t)
+
+	err := storage.SavePost(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []core.Post{post}
+	err = storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !posts[0].Saved {
+		t.Fatal("post should be saved after saving it")
+	}
+	if posts[0].Read {
+		t.Fatal("saving a post should not mark it read")
+	}
+}
+
+func SavePostAlreadyExists(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	post := CreateMockPost(storage, t)
+
+	err := storage.SavePost(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// attempt to save the same post again
+	err = storage.SavePost(context.Background(), account.ID, post.ID)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("saving a post twice should return an error")
+	}
+}
+
+func SavePostNotExist(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	err := storage.SavePost(context.Background(), account.ID, 999999999)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("saving a missing post should return an error")
+	}
+}
+
+func UnsavePost(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	post := CreateMockPost(storage, t)
+
+	err := storage.SavePost(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.UnsavePost(context.Background(), account.ID, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []core.Post{post}
+	err = storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if posts[0].Saved {
+		t.Fatal("post should not be saved after unsaving it")
+	}
+
+	// unsaving again should fail
+	err = storage.UnsavePost(context.Background(), account.ID, post.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("unsaving a missing post should return an error")
+	}
+}
+
+func ReadSavedPostsAfter(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	// save 3 posts and leave another alone
+	saved := make(map[int]bool)
+	for i := 0; i < 3; i++ {
+		post := CreateMockPost(storage, t)
+		err := storage.SavePost(context.Background(), account.ID, post.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved[post.ID] = true
+	}
+	CreateMockPost(storage, t)
+
+	// page through all posts two at a time
+	seen := make(map[int]bool)
+
+	var cursor core.Cursor
+	for {
+		posts, next, err := storage.ReadSavedPostsAfter(context.Background(), account.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, post := range posts {
+			if !saved[post.ID] {
+				t.Fatalf("post %v was not saved", post.ID)
+			}
+			if !post.Saved {
+				t.Fatalf("post %v should be marked saved", post.ID)
+			}
+			seen[post.ID] = true
+		}
+
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("want %v, got %v", 3, len(seen))
+	}
+}
+
+func ReadPostMarks(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	other, _ := CreateMockAccount(storage, t)
+
+	read := CreateMockPost(storage, t)
+	saved := CreateMockPost(storage, t)
+	unmarked := CreateMockPost(storage, t)
+
+	err := storage.MarkPostRead(context.Background(), account.ID, read.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SavePost(context.Background(), account.ID, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []core.Post{read, saved, unmarked}
+	err = storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !posts[0].Read || posts[0].Saved {
+		t.Fatalf("post %v should only be read", posts[0].ID)
+	}
+	if posts[1].Read || !posts[1].Saved {
+		t.Fatalf("post %v should only be saved", posts[1].ID)
+	}
+	if posts[2].Read || posts[2].Saved {
+		t.Fatalf("post %v should not be marked", posts[2].ID)
+	}
+
+	// marks belong to a single account
+	err = storage.ReadPostMarks(context.Background(), other.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, post := range posts {
+		if post.Read || post.Saved {
+			t.Fatalf("post %v should not be marked for another account", post.ID)
+		}
+	}
+}
+
+func CountUnreadPosts(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+	other := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create 3 posts on the subscribed blog and read one of them
+	var posts []core.Post
+	for i := 0; i < 3; i++ {
+		post := NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, post)
+	}
+
+	err = storage.MarkPostRead(context.Background(), account.ID, posts[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := storage.CountUnreadPosts(context.Background(), account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counts[blog.ID] != 2 {
+		t.Fatalf("want %v, got %v", 2, counts[blog.ID])
+	}
+
+	// only subscribed blogs are counted
+	if _, ok := counts[other.ID]; ok {
+		t.Fatalf("blog %v should not be counted", other.ID)
+	}
+}
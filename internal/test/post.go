@@ -3,7 +3,11 @@ package test
 import (
 	"context"
 	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/theandrew168/bloggulus/internal/core"
 )
@@ -26,9 +30,60 @@ func CreatePostAlreadyExists(storage core.Storage, t *testing.T) {
 	}
 }
 
-func ReadPost(storage core.Storage, t *testing.T) {
+func CreatePostGUIDAlreadyExists(storage core.Storage, t *testing.T) {
 	post := CreateMockPost(storage, t)
 
+	// the same entry under a different URL is still the same post
+	post.URL = RandomURL(32)
+	err := storage.CreatePost(context.Background(), &post)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate post guid should return an error")
+	}
+}
+
+func CreatePostCanonical(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// links are kept as given (the site may not support https)
+	post := NewMockPost(blog)
+	link := strings.Replace(post.URL, "https://", "http://", 1) + "?utm_source=rss#comments"
+	post.URL = link
+
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.URL != link {
+		t.Fatalf("want %v, got %v", link, got.URL)
+	}
+
+	// but the same post linked in a slightly different way is a duplicate
+	duplicate := NewMockPost(blog)
+	duplicate.URL = strings.ToUpper(strings.TrimSuffix(link, "?utm_source=rss#comments"))
+	err = storage.CreatePost(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate post should return an error")
+	}
+}
+
+func ReadPost(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	published := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	post := NewMockPost(blog)
+	post.Published = &published
+
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	got, err := storage.ReadPost(context.Background(), post.ID)
 	if err != nil {
 		t.Fatal(err)
@@ -37,6 +92,133 @@ func ReadPost(storage core.Storage, t *testing.T) {
 	if got.ID != post.ID {
 		t.Fatalf("want %v, got %v", post.ID, got.ID)
 	}
+	if got.GUID != post.GUID {
+		t.Fatalf("want %v, got %v", post.GUID, got.GUID)
+	}
+	if got.Summary != post.Summary {
+		t.Fatalf("want %v, got %v", post.Summary, got.Summary)
+	}
+	if got.Author != post.Author {
+		t.Fatalf("want %v, got %v", post.Author, got.Author)
+	}
+	if got.Published == nil || !got.Published.Equal(published) {
+		t.Fatalf("want %v, got %v", published, got.Published)
+	}
+	if got.FirstSeen.IsZero() || !got.FirstSeen.Equal(post.FirstSeen) {
+		t.Fatalf("want %v, got %v", post.FirstSeen, got.FirstSeen)
+	}
+}
+
+func ReadPostPublished(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	published := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	post := NewMockPost(blog)
+	post.Published = &published
+
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Published == nil || !got.Published.Equal(published) {
+		t.Fatalf("want %v, got %v", published, got.Published)
+	}
+}
+
+func CreatePostUndated(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// the feed didn't say when this post was published or updated
+	post := NewMockPost(blog)
+	post.Updated = time.Time{}
+
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// undated posts should be sorted by when they were first seen
+	if post.FirstSeen.IsZero() {
+		t.Fatal("post first seen should be set after creation")
+	}
+	if !post.Updated.Equal(post.FirstSeen) {
+		t.Fatalf("want %v, got %v", post.FirstSeen, post.Updated)
+	}
+	if post.Published != nil {
+		t.Fatalf("want nil, got %v", post.Published)
+	}
+}
+
+func UpdatePost(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	// simulate an author editing the post
+	post.Title = RandomString(32)
+	post.Updated = post.Updated.Add(time.Hour)
+	post.Body = RandomString(256)
+
+	err := storage.UpdatePost(context.Background(), post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != post.Title {
+		t.Fatalf("want %v, got %v", post.Title, got.Title)
+	}
+	if !got.Updated.Equal(post.Updated.Truncate(time.Microsecond)) {
+		t.Fatalf("want %v, got %v", post.Updated, got.Updated)
+	}
+}
+
+func UpdatePostNotExist(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+	post := NewMockPost(blog)
+	post.ID = 999999999
+
+	err := storage.UpdatePost(context.Background(), post)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("updating a missing post should return an error")
+	}
+}
+
+func UpdatePostGUID(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	guid := RandomString(32)
+	err := storage.UpdatePostGUID(context.Background(), post.ID, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GUID != guid {
+		t.Fatalf("want %v, got %v", guid, got.GUID)
+	}
+	if got.Title != post.Title {
+		t.Fatalf("want %v, got %v", post.Title, got.Title)
+	}
+}
+
+func UpdatePostGUIDNotExist(storage core.Storage, t *testing.T) {
+	err := storage.UpdatePostGUID(context.Background(), 999999999, RandomString(32))
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("updating a missing post should return an error")
+	}
 }
 
 func ReadPosts(storage core.Storage, t *testing.T) {
@@ -125,6 +307,238 @@ func SearchPosts(storage core.Storage, t *testing.T) {
 	}
 }
 
+func ReadPostsAfter(storage core.Storage, t *testing.T) {
+	CreateMockPost(storage, t)
+	CreateMockPost(storage, t)
+	CreateMockPost(storage, t)
+	CreateMockPost(storage, t)
+	post := CreateMockPost(storage, t)
+
+	limit := 3
+	posts, next, err := storage.ReadPostsAfter(context.Background(), core.Cursor{}, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != limit {
+		t.Fatalf("want %v, got %v", limit, len(posts))
+	}
+
+	// most recent post should be the one just added
+	if posts[0].ID != post.ID {
+		t.Fatalf("want %v, got %v", post.ID, posts[0].ID)
+	}
+
+	if next.IsZero() {
+		t.Fatal("expected cursor for the next page")
+	}
+
+	// the next page should pick up right where this one left off
+	more, _, err := storage.ReadPostsAfter(context.Background(), next, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(more) != limit {
+		t.Fatalf("want %v, got %v", limit, len(more))
+	}
+
+	last := posts[len(posts)-1]
+	if more[0].ID == last.ID || more[0].Updated.After(last.Updated) {
+		t.Fatalf("next page overlaps previous page")
+	}
+}
+
+func ReadPostsByBlogAfter(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// create 5 posts sharing a timestamp so that only IDs break ties
+	updated := RandomTime()
+	for i := 0; i < 5; i++ {
+		post := core.NewPost(RandomURL(32), RandomString(32), updated, blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// page through all posts two at a time
+	seen := make(map[int]bool)
+	pages := 0
+
+	var cursor core.Cursor
+	for {
+		posts, next, err := storage.ReadPostsByBlogAfter(context.Background(), blog.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, post := range posts {
+			if seen[post.ID] {
+				t.Fatalf("post %v returned more than once", post.ID)
+			}
+			seen[post.ID] = true
+		}
+
+		pages++
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("want %v, got %v", 5, len(seen))
+	}
+
+	if pages != 3 {
+		t.Fatalf("want %v, got %v", 3, pages)
+	}
+}
+
+func ReadPostsByTagAfter(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	// create 5 tagged posts sharing a timestamp so that only IDs break ties
+	updated := RandomTime()
+	for i := 0; i < 5; i++ {
+		post := core.NewPost(RandomURL(32), RandomString(32), updated, blog)
+		post.Body = "all about " + tag.Name
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// and one that isn't tagged
+	post := core.NewPost(RandomURL(32), RandomString(32), updated, blog)
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// page through all tagged posts two at a time
+	seen := make(map[int]bool)
+	pages := 0
+
+	var cursor core.Cursor
+	for {
+		posts, next, err := storage.ReadPostsByTagAfter(context.Background(), tag.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, post := range posts {
+			if seen[post.ID] {
+				t.Fatalf("post %v returned more than once", post.ID)
+			}
+			if !subset([]string{tag.Name}, post.Tags) {
+				t.Fatalf("want superset of %v, got %v", []string{tag.Name}, post.Tags)
+			}
+			seen[post.ID] = true
+		}
+
+		pages++
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("want %v, got %v", 5, len(seen))
+	}
+
+	if pages != 3 {
+		t.Fatalf("want %v, got %v", 3, pages)
+	}
+
+	// the tag knows how many posts it has
+	got, err := storage.ReadTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.PostCount != 5 {
+		t.Fatalf("want %v, got %v", 5, got.PostCount)
+	}
+}
+
+func SearchPostsSnippet(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// a term unique to this post (digits keep it a single word)
+	term := "quokka" + strconv.Itoa(rand.Intn(1000000000))
+
+	post := core.NewPost(RandomURL(32), RandomString(32), RandomTime(), blog)
+	post.Body = "The <quick> brown " + term + " jumps over the lazy dog."
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := storage.SearchPosts(context.Background(), term, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(posts))
+	}
+
+	// the body is escaped while the matched term is highlighted
+	snippet := posts[0].Snippet
+	if !strings.Contains(snippet, "<mark>"+term+"</mark>") {
+		t.Fatalf("want highlighted %v, got %v", term, snippet)
+	}
+	if !strings.Contains(snippet, "&lt;quick&gt;") {
+		t.Fatalf("want escaped body, got %v", snippet)
+	}
+}
+
+func SearchPostsAfter(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+	q := "python rust"
+
+	// create 5 searchable posts
+	for i := 0; i < 5; i++ {
+		post := core.NewPost(RandomURL(32), q, RandomTime(), blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	limit := 3
+	posts, next, err := storage.SearchPostsAfter(context.Background(), q, core.Cursor{}, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != limit {
+		t.Fatalf("want %v, got %v", limit, len(posts))
+	}
+
+	if next.IsZero() {
+		t.Fatal("expected cursor for the next page")
+	}
+
+	more, _, err := storage.SearchPostsAfter(context.Background(), q, next, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// results should never repeat across pages
+	for _, a := range posts {
+		for _, b := range more {
+			if a.ID == b.ID {
+				t.Fatalf("post %v returned more than once", a.ID)
+			}
+		}
+	}
+}
+
 func CountPosts(storage core.Storage, t *testing.T) {
 	CreateMockPost(storage, t)
 
@@ -139,6 +553,19 @@ func CountPosts(storage core.Storage, t *testing.T) {
 	}
 }
 
+func CountPostsByBlog(storage core.Storage, t *testing.T) {
+	post := CreateMockPost(storage, t)
+
+	count, err := storage.CountPostsByBlog(context.Background(), post.Blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("want %v, got %v", 1, count)
+	}
+}
+
 func CountSearchPosts(storage core.Storage, t *testing.T) {
 	blog := CreateMockBlog(storage, t)
 
@@ -2,6 +2,7 @@ package test
 
 import (
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -20,10 +21,24 @@ func RandomString(n int) string {
 	return string(buf)
 }
 
+// RandomURL returns a random URL that is already in canonical form.
 func RandomURL(n int) string {
-	return "https://" + RandomString(n)
+	return "https://" + strings.ToLower(RandomString(n)) + ".com/"
 }
 
 func RandomTime() time.Time {
 	return time.Now()
 }
+
+// RandomWord returns a random lowercase word that full text search will
+// treat as a single term.
+func RandomWord(n int) string {
+	valid := "abcdefghijklmnopqrstuvwxyz"
+
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = valid[rand.Intn(len(valid))]
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,196 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func FilterPosts(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+	other := CreateMockBlog(storage, t)
+	word := RandomWord(16)
+
+	now := time.Now()
+	posts := []struct {
+		blog    core.Blog
+		body    string
+		updated time.Time
+	}{
+		{blog, word + " " + tag.Name, now},
+		{blog, word, now},
+		{blog, word + " " + tag.Name, now.AddDate(-1, 0, 0)},
+		{other, word + " " + tag.Name, now},
+	}
+
+	var want core.Post
+	for i, p := range posts {
+		post := core.NewPost(RandomURL(32), RandomString(32), p.updated, p.blog)
+		post.Body = p.body
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			want = post
+		}
+	}
+
+	// only the first post matches every filter (tags match aliases too)
+	search := core.SearchRequest{
+		Text:    word,
+		BlogIDs: []int{blog.ID},
+		Tags:    []string{tag.Aliases[0]},
+		Since:   now.AddDate(0, 0, -30),
+		Until:   now.Add(time.Hour),
+	}
+
+	got, next, err := storage.FilterPosts(context.Background(), search, core.Cursor{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].ID != want.ID {
+		t.Fatalf("want only post %v, got %v", want.ID, got)
+	}
+	if !next.IsZero() {
+		t.Fatalf("want zero cursor, got %v", next)
+	}
+	if got[0].Snippet == "" {
+		t.Fatalf("want a snippet for a text search")
+	}
+
+	count, err := storage.CountFilteredPosts(context.Background(), search)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("want %v, got %v", 1, count)
+	}
+
+	// while text alone matches all of them
+	count, err = storage.CountFilteredPosts(context.Background(), core.SearchRequest{Text: word})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != len(posts) {
+		t.Fatalf("want %v, got %v", len(posts), count)
+	}
+
+	// and unknown tags match nothing
+	search = core.SearchRequest{Text: word, Tags: []string{RandomWord(16)}}
+	count, err = storage.CountFilteredPosts(context.Background(), search)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Fatalf("want %v, got %v", 0, count)
+	}
+}
+
+func FilterPostsAfter(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+	word := RandomWord(16)
+
+	// create 5 matching posts a day apart
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		post := core.NewPost(RandomURL(32), RandomString(32), now.AddDate(0, 0, -i), blog)
+		post.Body = word
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sorts := []string{core.SortRelevance, core.SortDate}
+	for _, sort := range sorts {
+		search := core.SearchRequest{
+			Text: word,
+			Sort: sort,
+		}
+
+		// page through all posts two at a time
+		seen := make(map[int]bool)
+		pages := 0
+
+		var last time.Time
+		var cursor core.Cursor
+		for {
+			posts, next, err := storage.FilterPosts(context.Background(), search, cursor, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, post := range posts {
+				if seen[post.ID] {
+					t.Fatalf("%s: post %v returned more than once", sort, post.ID)
+				}
+				seen[post.ID] = true
+
+				// newest first when sorting by date
+				if sort == core.SortDate && !last.IsZero() && post.Updated.After(last) {
+					t.Fatalf("%s: post %v is out of order", sort, post.ID)
+				}
+				last = post.Updated
+			}
+
+			pages++
+			if next.IsZero() {
+				break
+			}
+			cursor = next
+		}
+
+		if len(seen) != 5 {
+			t.Fatalf("%s: want %v, got %v", sort, 5, len(seen))
+		}
+
+		if pages != 3 {
+			t.Fatalf("%s: want %v, got %v", sort, 3, pages)
+		}
+	}
+}
+
+func MatchPosts(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	var ids []int
+	for _, body := range []string{tag.Name, RandomWord(16)} {
+		post := core.NewPost(RandomURL(32), RandomString(32), time.Now(), blog)
+		post.Body = body
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, post.ID)
+	}
+
+	// only the first post has the tag
+	search := core.SearchRequest{Tags: []string{tag.Name}}
+	got, err := storage.MatchPosts(context.Background(), search, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0] != ids[0] {
+		t.Fatalf("want %v, got %v", ids[:1], got)
+	}
+
+	// posts that weren't asked about are left out
+	got, err = storage.MatchPosts(context.Background(), core.SearchRequest{}, ids[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0] != ids[1] {
+		t.Fatalf("want %v, got %v", ids[1:], got)
+	}
+}
@@ -0,0 +1,165 @@
+package test
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// SMTPMessage is an email received by an SMTPServer.
+type SMTPMessage struct {
+	From    string
+	To      []string
+	Subject string
+	// decoded from the message's transfer encoding
+	Body string
+}
+
+// SMTPServer is a local stand-in for an SMTP server that keeps every
+// message it receives. It doesn't support STARTTLS or AUTH.
+type SMTPServer struct {
+	Host string
+	Port string
+
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []SMTPMessage
+}
+
+// NewSMTPServer starts an SMTP server on a random local port that is
+// stopped when the test finishes.
+func NewSMTPServer(t testing.TB) *SMTPServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := SMTPServer{
+		Host:     host,
+		Port:     port,
+		listener: l,
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	return &s
+}
+
+// Messages returns the messages received so far.
+func (s *SMTPServer) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]SMTPMessage, len(s.messages))
+	copy(messages, s.messages)
+	return messages
+}
+
+func (s *SMTPServer) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	tp.PrintfLine("220 localhost ESMTP")
+
+	var msg SMTPMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO", "EHLO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			msg = SMTPMessage{From: smtpAddress(arg)}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, smtpAddress(arg))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			err = s.receive(&msg, tp.DotReader())
+			if err != nil {
+				tp.PrintfLine("554 %s", err)
+				continue
+			}
+			tp.PrintfLine("250 OK")
+		case "RSET", "NOOP":
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s *SMTPServer) receive(msg *SMTPMessage, r io.Reader) error {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return err
+	}
+
+	var dec mime.WordDecoder
+	msg.Subject, err = dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+
+	body := m.Body
+	if strings.EqualFold(m.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	msg.Body = string(b)
+
+	s.mu.Lock()
+	s.messages = append(s.messages, *msg)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// smtpAddress extracts the address from a MAIL or RCPT argument such as
+// "FROM:<alice@example.com> BODY=8BITMIME".
+func smtpAddress(arg string) string {
+	start := strings.Index(arg, "<")
+	end := strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return arg[start+1 : end]
+}
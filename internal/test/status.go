@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func RecordSyncStatus(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// record a couple failures
+	for i := 1; i <= 2; i++ {
+		status := core.NewSyncStatus(blog.ID, time.Now())
+		status.HTTPStatus = 500
+		status.LastError = RandomString(32)
+
+		err := storage.RecordSyncStatus(context.Background(), &status)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.FailureCount != i {
+			t.Fatalf("want %v, got %v", i, status.FailureCount)
+		}
+		if status.LastSuccessAt != nil {
+			t.Fatalf("want nil, got %v", status.LastSuccessAt)
+		}
+	}
+
+	// a success should reset the failure count
+	status := core.NewSyncStatus(blog.ID, time.Now())
+	status.HTTPStatus = 200
+	status.NewPosts = 3
+
+	err := storage.RecordSyncStatus(context.Background(), &status)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.FailureCount != 0 {
+		t.Fatalf("want %v, got %v", 0, status.FailureCount)
+	}
+	if status.LastSuccessAt == nil {
+		t.Fatal("last success should be set after a successful sync")
+	}
+}
+
+func ReadSyncStatus(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	status := core.NewSyncStatus(blog.ID, time.Now())
+	status.HTTPStatus = 404
+	status.LastError = RandomString(32)
+	status.UpdatedPosts = 2
+
+	err := storage.RecordSyncStatus(context.Background(), &status)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadSyncStatus(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.HTTPStatus != status.HTTPStatus {
+		t.Fatalf("want %v, got %v", status.HTTPStatus, got.HTTPStatus)
+	}
+	if got.LastError != status.LastError {
+		t.Fatalf("want %v, got %v", status.LastError, got.LastError)
+	}
+	if got.FailureCount != 1 {
+		t.Fatalf("want %v, got %v", 1, got.FailureCount)
+	}
+	if got.UpdatedPosts != status.UpdatedPosts {
+		t.Fatalf("want %v, got %v", status.UpdatedPosts, got.UpdatedPosts)
+	}
+}
+
+func ReadSyncStatusNotExist(storage core.Storage, t *testing.T) {
+	blog := CreateMockBlog(storage, t)
+
+	// blog hasn't been synced yet
+	_, err := storage.ReadSyncStatus(context.Background(), blog.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("unsynced blog should not have a status")
+	}
+}
+
+func CreateMockFailingBlog(storage core.Storage, t *testing.T) core.Blog {
+	t.Helper()
+
+	blog := CreateMockBlog(storage, t)
+
+	status := core.NewSyncStatus(blog.ID, time.Now())
+	status.LastError = RandomString(32)
+
+	err := storage.RecordSyncStatus(context.Background(), &status)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return blog
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func CreateSubscription(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribed, err := storage.IsSubscribed(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !subscribed {
+		t.Fatal("account should be subscribed after creation")
+	}
+}
+
+func CreateSubscriptionAlreadyExists(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// attempt to subscribe to the same blog again
+	err = storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate subscription should return an error")
+	}
+}
+
+func CreateSubscriptionBlogNotExist(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, 999999999)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("subscribing to a missing blog should return an error")
+	}
+}
+
+func ReadSubscriptions(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+
+	// subscribe to a few blogs but not to another
+	for i := 0; i < 3; i++ {
+		blog := CreateMockBlog(storage, t)
+		err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := CreateMockBlog(storage, t)
+
+	blogs, err := storage.ReadSubscriptions(context.Background(), account.ID, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blogs) != 3 {
+		t.Fatalf("want %v, got %v", 3, len(blogs))
+	}
+	for _, blog := range blogs {
+		if blog.ID == other.ID {
+			t.Fatalf("blog %v should not be a subscription", other.ID)
+		}
+	}
+}
+
+func ReadSubscriptionsUnread(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create 2 posts and read one of them
+	for i := 0; i < 2; i++ {
+		post := NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i == 0 {
+			err = storage.MarkPostRead(context.Background(), account.ID, post.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	subscriptions, err := storage.ReadSubscriptions(context.Background(), account.ID, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(subscriptions) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(subscriptions))
+	}
+	if subscriptions[0].Unread != 1 {
+		t.Fatalf("want %v, got %v", 1, subscriptions[0].Unread)
+	}
+}
+
+func DeleteSubscription(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.DeleteSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribed, err := storage.IsSubscribed(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscribed {
+		t.Fatal("account should not be subscribed after deletion")
+	}
+
+	// unsubscribing again should fail
+	err = storage.DeleteSubscription(context.Background(), account.ID, blog.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleting a missing subscription should return an error")
+	}
+}
+
+func ReadSubscribedPostsAfter(storage core.Storage, t *testing.T) {
+	account, _ := CreateMockAccount(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	err := storage.CreateSubscription(context.Background(), account.ID, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create 3 posts on the subscribed blog and one elsewhere
+	for i := 0; i < 3; i++ {
+		post := NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := CreateMockPost(storage, t)
+
+	// page through all posts two at a time
+	seen := make(map[int]bool)
+
+	var cursor core.Cursor
+	for {
+		posts, next, err := storage.ReadSubscribedPostsAfter(context.Background(), account.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, post := range posts {
+			if post.Blog.ID != blog.ID {
+				t.Fatalf("post %v is not from a subscribed blog", post.ID)
+			}
+			seen[post.ID] = true
+		}
+
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("want %v, got %v", 3, len(seen))
+	}
+	if seen[other.ID] {
+		t.Fatalf("post %v should not be included", other.ID)
+	}
+}
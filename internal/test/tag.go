@@ -0,0 +1,356 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func CreateTag(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+
+	// tag should have an ID after creation
+	if tag.ID == 0 {
+		t.Fatal("tag id after creation should be nonzero")
+	}
+}
+
+func CreateTagAlreadyExists(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+
+	// attempt to create the same tag again (ignoring case)
+	duplicate := core.NewTag(strings.ToUpper(tag.Name))
+	err := storage.CreateTag(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate tag should return an error")
+	}
+
+	// names already used as an alias are taken as well
+	duplicate = core.NewTag(tag.Aliases[0])
+	err = storage.CreateTag(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("tag named after an alias should return an error")
+	}
+
+	// as are names repeated within the new tag itself
+	name := RandomWord(16)
+	duplicate = core.NewTag(name, strings.ToUpper(name))
+	err = storage.CreateTag(context.Background(), &duplicate)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("tag aliased to its own name should return an error")
+	}
+}
+
+func ReadTag(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+
+	got, err := storage.ReadTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != tag.ID {
+		t.Fatalf("want %v, got %v", tag.ID, got.ID)
+	}
+	if got.Name != tag.Name {
+		t.Fatalf("want %v, got %v", tag.Name, got.Name)
+	}
+	if !subset(tag.Aliases, got.Aliases) {
+		t.Fatalf("want %v, got %v", tag.Aliases, got.Aliases)
+	}
+}
+
+func ReadTagByName(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+
+	// tags can be found by name or alias regardless of case
+	names := []string{
+		tag.Name,
+		strings.ToUpper(tag.Name),
+		tag.Aliases[0],
+	}
+	for _, name := range names {
+		got, err := storage.ReadTagByName(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.ID != tag.ID {
+			t.Fatalf("want %v, got %v", tag.ID, got.ID)
+		}
+	}
+
+	_, err := storage.ReadTagByName(context.Background(), RandomWord(16))
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("unknown tag name should not exist")
+	}
+}
+
+func ReadTags(storage core.Storage, t *testing.T) {
+	CreateMockTag(storage, t)
+	CreateMockTag(storage, t)
+	CreateMockTag(storage, t)
+	CreateMockTag(storage, t)
+	CreateMockTag(storage, t)
+
+	limit := 3
+	offset := 0
+	tags, err := storage.ReadTags(context.Background(), limit, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tags) != limit {
+		t.Fatalf("want %v, got %v", limit, len(tags))
+	}
+}
+
+func DeleteTag(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+
+	err := storage.DeleteTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storage.ReadTag(context.Background(), tag.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted tag should not exist")
+	}
+
+	// its aliases should be freed up as well
+	_, err = storage.ReadTagByName(context.Background(), tag.Aliases[0])
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleted tag's alias should not exist")
+	}
+
+	err = storage.DeleteTag(context.Background(), tag.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleting a missing tag should return an error")
+	}
+}
+
+func CreateTagAlias(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	alias := RandomWord(16)
+
+	err := storage.CreateTagAlias(context.Background(), tag.ID, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !subset([]string{alias}, got.Aliases) {
+		t.Fatalf("want superset of %v, got %v", []string{alias}, got.Aliases)
+	}
+
+	// the same alias can't be added twice
+	err = storage.CreateTagAlias(context.Background(), tag.ID, alias)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate alias should return an error")
+	}
+
+	// aliases can only be added to existing tags
+	err = storage.CreateTagAlias(context.Background(), 999999999, RandomWord(16))
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("alias for a missing tag should return an error")
+	}
+}
+
+func CreateTagAliasConcurrent(storage core.Storage, t *testing.T) {
+	tags := []core.Tag{
+		CreateMockTag(storage, t),
+		CreateMockTag(storage, t),
+	}
+	alias := RandomWord(16)
+
+	// both tags race to claim the same alias
+	errs := make([]error, len(tags))
+	var wg sync.WaitGroup
+	for i, tag := range tags {
+		wg.Add(1)
+		go func(i int, tag core.Tag) {
+			defer wg.Done()
+			errs[i] = storage.CreateTagAlias(context.Background(), tag.ID, alias)
+		}(i, tag)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		if !errors.Is(err, core.ErrExist) {
+			t.Fatal(err)
+		}
+	}
+
+	if created != 1 {
+		t.Fatalf("want %v, got %v", 1, created)
+	}
+}
+
+func DeleteTagAlias(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	alias := tag.Aliases[0]
+
+	err := storage.DeleteTagAlias(context.Background(), tag.ID, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadTag(context.Background(), tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Aliases) != 0 {
+		t.Fatalf("want no aliases, got %v", got.Aliases)
+	}
+
+	err = storage.DeleteTagAlias(context.Background(), tag.ID, alias)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("deleting a missing alias should return an error")
+	}
+}
+
+func TagPostOnCreate(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	// posts mentioning a tag's alias are assigned the tag
+	post := NewMockPost(blog)
+	post.Body = "all about " + tag.Aliases[0]
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !subset([]string{tag.Name}, got.Tags) {
+		t.Fatalf("want superset of %v, got %v", []string{tag.Name}, got.Tags)
+	}
+}
+
+func TagPostOnAlias(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+	alias := RandomWord(16)
+
+	post := NewMockPost(blog)
+	post.Body = "all about " + alias
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// adding an alias assigns the tag to existing posts
+	err = storage.CreateTagAlias(context.Background(), tag.ID, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !subset([]string{tag.Name}, got.Tags) {
+		t.Fatalf("want superset of %v, got %v", []string{tag.Name}, got.Tags)
+	}
+
+	// and removing it takes the tag away again
+	err = storage.DeleteTagAlias(context.Background(), tag.ID, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subset([]string{tag.Name}, got.Tags) {
+		t.Fatalf("want no %v, got %v", tag.Name, got.Tags)
+	}
+}
+
+func TagPostRank(storage core.Storage, t *testing.T) {
+	minor := CreateMockTag(storage, t)
+	major := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	// the tag mentioned most often should be listed first
+	post := NewMockPost(blog)
+	post.Body = strings.Join([]string{major.Name, minor.Name, major.Name, major.Name}, " ")
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{major.Name, minor.Name}
+	if len(got.Tags) != len(want) || got.Tags[0] != want[0] || got.Tags[1] != want[1] {
+		t.Fatalf("want %v, got %v", want, got.Tags)
+	}
+}
+
+func RetagPosts(storage core.Storage, t *testing.T) {
+	tag := CreateMockTag(storage, t)
+	blog := CreateMockBlog(storage, t)
+
+	post := NewMockPost(blog)
+	post.Body = "all about " + tag.Name
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.RetagPosts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// retagging should arrive at the same tags
+	got, err := storage.ReadPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !subset([]string{tag.Name}, got.Tags) {
+		t.Fatalf("want superset of %v, got %v", []string{tag.Name}, got.Tags)
+	}
+}
+
+func CreateMockTag(storage core.Storage, t *testing.T) core.Tag {
+	t.Helper()
+
+	// generate some random tag data
+	tag := NewMockTag()
+
+	// create an example tag
+	err := storage.CreateTag(context.Background(), &tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tag
+}
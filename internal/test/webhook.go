@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func CreateWebhook(storage core.Storage, t *testing.T) {
+	webhook := NewMockWebhook()
+	err := storage.CreateWebhook(context.Background(), &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if webhook.ID == 0 {
+		t.Fatal("webhook ID should be set after creation")
+	}
+}
+
+func ReadWebhook(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+
+	got, err := storage.ReadWebhook(context.Background(), webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.URL != webhook.URL || got.Secret != webhook.Secret || got.Query != webhook.Query {
+		t.Fatalf("want %v, got %v", webhook, got)
+	}
+	if len(got.Tags) != len(webhook.Tags) {
+		t.Fatalf("want %v, got %v", webhook.Tags, got.Tags)
+	}
+}
+
+func ReadWebhooks(storage core.Storage, t *testing.T) {
+	CreateMockWebhook(storage, t)
+	CreateMockWebhook(storage, t)
+
+	limit := 2
+	webhooks, err := storage.ReadWebhooks(context.Background(), limit, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(webhooks) != limit {
+		t.Fatalf("want %v, got %v", limit, len(webhooks))
+	}
+}
+
+func DeleteWebhook(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+
+	err := storage.DeleteWebhook(context.Background(), webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storage.ReadWebhook(context.Background(), webhook.ID)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("webhook should not exist after deletion")
+	}
+}
+
+func CreateWebhookDelivery(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+	post := CreateMockPost(storage, t)
+
+	delivery := core.NewWebhookDelivery(webhook.ID, post.ID, []byte(`{"event": "post.created"}`))
+	err := storage.CreateWebhookDelivery(context.Background(), &delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if delivery.ID == 0 {
+		t.Fatal("delivery ID should be set after creation")
+	}
+
+	// a post is only queued once per webhook
+	again := core.NewWebhookDelivery(webhook.ID, post.ID, []byte(`{"event": "post.created"}`))
+	err = storage.CreateWebhookDelivery(context.Background(), &again)
+	if !errors.Is(err, core.ErrExist) {
+		t.Fatal("duplicate delivery should return an error")
+	}
+}
+
+func CreateWebhookDeliveryNotExist(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+
+	delivery := core.NewWebhookDelivery(webhook.ID, 999999999, []byte(`{}`))
+	err := storage.CreateWebhookDelivery(context.Background(), &delivery)
+	if !errors.Is(err, core.ErrNotExist) {
+		t.Fatal("delivery of a missing post should return an error")
+	}
+}
+
+func ReadWebhookDeliveries(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+
+	var deliveries []core.WebhookDelivery
+	for i := 0; i < 2; i++ {
+		post := CreateMockPost(storage, t)
+		delivery := core.NewWebhookDelivery(webhook.ID, post.ID, []byte(`{"event": "post.created"}`))
+		err := storage.CreateWebhookDelivery(context.Background(), &delivery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	got, err := storage.ReadWebhookDeliveries(context.Background(), webhook.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newest first
+	if len(got) != 2 || got[0].ID != deliveries[1].ID || got[1].ID != deliveries[0].ID {
+		t.Fatalf("want %v, got %v", deliveries, got)
+	}
+	if got[0].Status != core.DeliveryPending {
+		t.Fatalf("want %v, got %v", core.DeliveryPending, got[0].Status)
+	}
+	if string(got[0].Payload) != string(deliveries[1].Payload) {
+		t.Fatalf("want %s, got %s", deliveries[1].Payload, got[0].Payload)
+	}
+}
+
+func UpdateWebhookDelivery(storage core.Storage, t *testing.T) {
+	webhook := CreateMockWebhook(storage, t)
+	post := CreateMockPost(storage, t)
+
+	delivery := core.NewWebhookDelivery(webhook.ID, post.ID, []byte(`{}`))
+	err := storage.CreateWebhookDelivery(context.Background(), &delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the delivery is due right away
+	due, err := storage.ReadDueWebhookDeliveries(context.Background(), time.Now(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !containsDelivery(due, delivery.ID) {
+		t.Fatalf("expected delivery %v to be due", delivery.ID)
+	}
+
+	// record a failed attempt to be retried later
+	now := time.Now()
+	delivery.Attempts = 1
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = now.Add(time.Hour)
+	delivery.HTTPStatus = 500
+	delivery.LastError = "500 Internal Server Error"
+
+	err = storage.UpdateWebhookDelivery(context.Background(), delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due, err = storage.ReadDueWebhookDeliveries(context.Background(), now, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if containsDelivery(due, delivery.ID) {
+		t.Fatalf("delivery %v should not be due until its next attempt", delivery.ID)
+	}
+
+	got, err := storage.ReadWebhookDeliveries(context.Background(), webhook.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].Attempts != 1 || got[0].HTTPStatus != 500 || got[0].LastAttemptAt == nil {
+		t.Fatalf("want %v, got %v", delivery, got)
+	}
+}
+
+func NewMockWebhook() core.Webhook {
+	// nothing listens on port 1 (so any stray deliveries fail quickly) and
+	// the random tag keeps other tests' posts from being sent
+	webhook := core.NewWebhook(
+		"http://127.0.0.1:1/"+RandomString(16),
+		RandomString(32),
+		[]string{RandomString(16)},
+		"",
+	)
+	return webhook
+}
+
+func CreateMockWebhook(storage core.Storage, t *testing.T) core.Webhook {
+	t.Helper()
+
+	webhook := NewMockWebhook()
+	err := storage.CreateWebhook(context.Background(), &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return webhook
+}
+
+func containsDelivery(deliveries []core.WebhookDelivery, id int) bool {
+	for _, delivery := range deliveries {
+		if delivery.ID == id {
+			return true
+		}
+	}
+	return false
+}
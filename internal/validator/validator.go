@@ -1,6 +1,10 @@
 // Input validation helper based on Alex Edwards' package from Let's Go Further
 package validator
 
+import (
+	"net/url"
+)
+
 type Validator struct {
 	Errors map[string]string
 }
@@ -27,3 +31,13 @@ func (v *Validator) Check(ok bool, key, message string) {
 		v.AddError(key, message)
 	}
 }
+
+// IsURL reports whether s is an absolute http(s) URL
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/validator"
+)
+
+// accountForm holds what was submitted to the login or register page so
+// that it can be shown again alongside any errors.
+type accountForm struct {
+	Account  *core.Account
+	Search   string
+	Username string
+	Next     string
+	Errors   map[string]string
+}
+
+func (app *Application) HandleLoginForm(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+
+	// already logged in
+	if contextGetAccount(r) != nil {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	form := accountForm{
+		Next: next,
+	}
+	app.renderAccountForm(w, r, 200, "login.page.tmpl", form)
+}
+
+func (app *Application) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		app.badRequestResponse(w, r)
+		return
+	}
+
+	form := accountForm{
+		Username: strings.TrimSpace(r.PostForm.Get("username")),
+		Next:     safeRedirect(r.PostForm.Get("next")),
+	}
+	password := r.PostForm.Get("password")
+
+	v := validator.New()
+	v.Check(form.Username != "", "username", "Username must not be empty")
+	v.Check(password != "", "password", "Password must not be empty")
+	if !v.Valid() {
+		form.Errors = v.Errors
+		app.renderAccountForm(w, r, 422, "login.page.tmpl", form)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	account, err := app.storage.ReadAccountByUsername(ctx, form.Username)
+	if err != nil && !errors.Is(err, core.ErrNotExist) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	ok := false
+	if err == nil {
+		ok, err = account.PasswordMatches(password)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if !ok {
+		v.AddError("form", "Invalid username or password")
+		form.Errors = v.Errors
+		app.renderAccountForm(w, r, 422, "login.page.tmpl", form)
+		return
+	}
+
+	err = app.startSession(ctx, w, r, account)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, form.Next, http.StatusSeeOther)
+}
+
+func (app *Application) HandleRegisterForm(w http.ResponseWriter, r *http.Request) {
+	// already logged in
+	if contextGetAccount(r) != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	app.renderAccountForm(w, r, 200, "register.page.tmpl", accountForm{})
+}
+
+func (app *Application) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		app.badRequestResponse(w, r)
+		return
+	}
+
+	form := accountForm{
+		Username: strings.TrimSpace(r.PostForm.Get("username")),
+	}
+	password := r.PostForm.Get("password")
+
+	v := validator.New()
+	v.Check(len(form.Username) >= core.MinUsernameLength, "username", fmt.Sprintf("Username must be at least %d characters long", core.MinUsernameLength))
+	v.Check(len(form.Username) <= core.MaxUsernameLength, "username", fmt.Sprintf("Username must not be more than %d characters long", core.MaxUsernameLength))
+	v.Check(core.IsUsername(form.Username), "username", "Username must only contain letters, digits, dashes and underscores")
+	v.Check(len(password) >= core.MinPasswordLength, "password", fmt.Sprintf("Password must be at least %d characters long", core.MinPasswordLength))
+	v.Check(len(password) <= core.MaxPasswordLength, "password", fmt.Sprintf("Password must not be more than %d bytes long", core.MaxPasswordLength))
+	if !v.Valid() {
+		form.Errors = v.Errors
+		app.renderAccountForm(w, r, 422, "register.page.tmpl", form)
+		return
+	}
+
+	account, err := core.NewAccount(form.Username, password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.storage.CreateAccount(ctx, &account)
+	if err != nil {
+		if errors.Is(err, core.ErrExist) {
+			v.AddError("username", "Username is already taken")
+			form.Errors = v.Errors
+			app.renderAccountForm(w, r, 422, "register.page.tmpl", form)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logger.Printf("created account %d %q\n", account.ID, account.Username)
+
+	// new accounts start out logged in
+	err = app.startSession(ctx, w, r, account)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (app *Application) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+
+		// the session may have expired already
+		err = app.storage.DeleteSession(ctx, core.HashSessionToken(cookie.Value))
+		if err != nil && !errors.Is(err, core.ErrNotExist) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	clearSessionCookie(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// startSession logs the account in by setting a new session's cookie.
+func (app *Application) startSession(ctx context.Context, w http.ResponseWriter, r *http.Request, account core.Account) error {
+	session, token, err := core.NewSession(account.ID)
+	if err != nil {
+		return err
+	}
+
+	err = app.storage.CreateSession(ctx, &session)
+	if err != nil {
+		return err
+	}
+
+	setSessionCookie(w, r, token, session.Expiry)
+	return nil
+}
+
+func (app *Application) renderAccountForm(w http.ResponseWriter, r *http.Request, status int, tmpl string, form accountForm) {
+	files := []string{
+		tmpl,
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// render to a temp buffer so that errors can still be reported
+	var buf bytes.Buffer
+	err = ts.Execute(&buf, form)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,319 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+	"github.com/theandrew168/bloggulus/internal/web"
+)
+
+func TestHandleRegister(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	username := test.RandomString(32)
+	form := url.Values{
+		"username": {username},
+		"password": {test.RandomString(32)},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+
+	// new accounts should be logged in right away
+	cookie := sessionCookie(resp)
+	if cookie == nil {
+		t.Fatal("expected a session cookie")
+	}
+
+	account, err := storage.ReadAccountBySession(context.Background(), core.HashSessionToken(cookie.Value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Username != username {
+		t.Fatalf("want %v, got %v", username, account.Username)
+	}
+
+	// registering the same username again should fail
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 422 {
+		t.Fatalf("want %v, got %v", 422, resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "already taken") {
+		t.Fatalf("expected error about the username on page")
+	}
+}
+
+func TestHandleLogin(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, password := test.CreateMockAccount(storage, t)
+	blog := test.CreateMockBlog(storage, t)
+
+	form := url.Values{
+		"username": {account.Username},
+		"password": {password},
+		"next":     {fmt.Sprintf("/blog/%d", blog.ID)},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+
+	// the login should lead back to where it started
+	if resp.Header.Get("Location") != form.Get("next") {
+		t.Fatalf("want %v, got %v", form.Get("next"), resp.Header.Get("Location"))
+	}
+
+	cookie := sessionCookie(resp)
+	if cookie == nil {
+		t.Fatal("expected a session cookie")
+	}
+
+	// pages should now show who is logged in
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", form.Get("next"), nil)
+	r.AddCookie(cookie)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := string(body)
+	if !strings.Contains(page, account.Username) {
+		t.Fatalf("expected username on page")
+	}
+	if !strings.Contains(page, "Subscribe") {
+		t.Fatalf("expected subscribe button on page")
+	}
+}
+
+func TestHandleLoginInvalid(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+
+	form := url.Values{
+		"username": {account.Username},
+		"password": {test.RandomString(32)},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 422 {
+		t.Fatalf("want %v, got %v", 422, resp.StatusCode)
+	}
+	if sessionCookie(resp) != nil {
+		t.Fatal("unexpected session cookie")
+	}
+	if !strings.Contains(string(body), "Invalid username or password") {
+		t.Fatalf("expected error on page")
+	}
+}
+
+func TestHandleLogout(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/logout", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+
+	// the cookie should be cleared and the session ended
+	cookie := sessionCookie(resp)
+	if cookie == nil || cookie.MaxAge >= 0 {
+		t.Fatal("expected the session cookie to be cleared")
+	}
+
+	_, err := storage.ReadAccountBySession(context.Background(), core.HashSessionToken(token))
+	if err == nil {
+		t.Fatal("session should not exist after logging out")
+	}
+}
+
+func TestHandleIndexPersonal(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	cookie := &http.Cookie{Name: "session", Value: token}
+
+	// without any subscriptions the page suggests some blogs
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Browse the blogs") {
+		t.Fatalf("expected link to blogs on page")
+	}
+
+	// subscribe to one post's blog but not another's
+	post := test.CreateMockPost(storage, t)
+	other := test.CreateMockPost(storage, t)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", fmt.Sprintf("/blog/%d/subscribe", post.Blog.ID), nil)
+	r.AddCookie(cookie)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := string(body)
+	if !strings.Contains(page, "Your Feed") {
+		t.Fatalf("expected personal heading on page")
+	}
+	if !strings.Contains(page, post.Summary) {
+		t.Fatalf("expected subscribed post on page")
+	}
+	if strings.Contains(page, other.Summary) {
+		t.Fatalf("unexpected unsubscribed post on page")
+	}
+}
+
+func TestHandleSubscribeLoggedOut(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", fmt.Sprintf("/blog/%d/subscribe", blog.ID), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Location"), "/login") {
+		t.Fatalf("want %v, got %v", "/login", resp.Header.Get("Location"))
+	}
+}
+
+func sessionCookie(resp *http.Response) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "session" {
+			return cookie
+		}
+	}
+	return nil
+}
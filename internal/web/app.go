@@ -17,6 +17,8 @@ import (
 
 var (
 	pageSize     = 15
+	blogPageSize = 100
+	tagPageSize  = 100
 	queryTimeout = 3 * time.Second
 )
 
@@ -51,11 +53,36 @@ func NewApplication(storage core.Storage, logger *log.Logger) *Application {
 func (app *Application) Router() http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
+	r.Use(app.authenticate)
 
 	r.NotFound(app.notFoundResponse)
 	r.MethodNotAllowed(app.methodNotAllowedResponse)
 
 	r.Get("/", app.HandleIndex)
+	r.Get("/feed.atom", app.HandleFeedAtom)
+	r.Get("/feed.rss", app.HandleFeedRSS)
+	r.Get("/feed.json", app.HandleFeedJSON)
+	r.Get("/blog", app.HandleBlogs)
+	r.Get("/blog/{id}", app.HandleBlog)
+	r.Get("/tag", app.HandleTags)
+	r.Get("/tag/{name}", app.HandleTag)
+	r.Get("/post/{id}/visit", app.HandleVisitPost)
+	r.Get("/login", app.HandleLoginForm)
+	r.Post("/login", app.HandleLogin)
+	r.Get("/register", app.HandleRegisterForm)
+	r.Post("/register", app.HandleRegister)
+	r.Post("/logout", app.HandleLogout)
+
+	// account pages
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireAccount)
+
+		r.Post("/blog/{id}/subscribe", app.HandleSubscribe)
+		r.Post("/blog/{id}/unsubscribe", app.HandleUnsubscribe)
+		r.Get("/saved", app.HandleSaved)
+		r.Post("/post/{id}/save", app.HandleSavePost)
+		r.Post("/post/{id}/unsave", app.HandleUnsavePost)
+	})
 
 	return r
 }
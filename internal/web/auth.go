@@ -0,0 +1,104 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+// name of the cookie holding a login's session token
+const sessionCookieName = "session"
+
+// authenticate looks up the account of a request's session cookie (if
+// any) and makes it available to handlers via contextGetAccount.
+func (app *Application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookieName)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+
+		account, err := app.storage.ReadAccountBySession(ctx, core.HashSessionToken(cookie.Value))
+		if err != nil {
+			// expired or unknown sessions continue logged out
+			if errors.Is(err, core.ErrNotExist) {
+				clearSessionCookie(w, r)
+				next.ServeHTTP(w, r)
+				return
+			}
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		r = contextSetAccount(r, account)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAccount sends requests that aren't logged in to the login page
+// (and back again afterwards for pages that can be linked to).
+func (app *Application) requireAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contextGetAccount(r) == nil {
+			target := "/login"
+			if r.Method == http.MethodGet {
+				target += "?" + url.Values{"next": {r.URL.RequestURI()}}.Encode()
+			}
+			http.Redirect(w, r, target, http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setSessionCookie(w http.ResponseWriter, r *http.Request, token string, expiry time.Time) {
+	cookie := http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiry,
+		Secure:   isHTTPS(r),
+		HttpOnly: true,
+		// keeps other sites from submitting forms as the user
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
+}
+
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	cookie := http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   isHTTPS(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
+}
+
+// isHTTPS reports whether the request reached us (or the proxy in front of
+// us) over HTTPS.
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// safeRedirect only allows redirects to local paths (and not to other
+// sites via "//example.com" or "/\example.com").
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
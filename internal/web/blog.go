@@ -0,0 +1,191 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (app *Application) HandleBlogs(w http.ResponseWriter, r *http.Request) {
+	files := []string{
+		"blogs.page.tmpl",
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// check page param
+	p, err := strconv.Atoi(r.URL.Query().Get("p"))
+	if err != nil || p < 0 {
+		p = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// read one extra blog to determine if another page exists
+	blogs, err := app.storage.ReadBlogStats(ctx, blogPageSize+1, p*blogPageSize)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	more := len(blogs) > blogPageSize
+	if more {
+		blogs = blogs[:blogPageSize]
+	}
+
+	// logged in readers see how much is unread in the blogs they follow
+	var unread map[int]int
+	account := contextGetAccount(r)
+	if account != nil {
+		unread, err = app.storage.CountUnreadPosts(ctx, account.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	data := struct {
+		Account   *core.Account
+		Unread    map[int]int
+		MorePages bool
+		NextPage  string
+		Search    string
+		Blogs     []core.BlogStats
+	}{
+		Account:   account,
+		Unread:    unread,
+		MorePages: more,
+		NextPage:  "/blog?p=" + strconv.Itoa(p+1),
+		Blogs:     blogs,
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleBlog(w http.ResponseWriter, r *http.Request) {
+	files := []string{
+		"blog.page.tmpl",
+		"posts.partial.tmpl",
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	blog, err := app.storage.ReadBlog(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// an invalid cursor just starts over from the beginning
+	cursor, err := core.ParseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		cursor = core.Cursor{}
+	}
+
+	posts, next, err := app.storage.ReadPostsByBlogAfter(ctx, blog.ID, cursor, pageSize)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	count, err := app.storage.CountPostsByBlog(ctx, blog.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// logged in readers can subscribe to the blog (and see what's unread)
+	subscribed := false
+	unread := 0
+	account := contextGetAccount(r)
+	if account != nil {
+		subscribed, err = app.storage.IsSubscribed(ctx, account.ID, blog.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		counts, err := app.storage.CountUnreadPosts(ctx, account.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		unread = counts[blog.ID]
+	}
+
+	err = app.markPosts(ctx, r, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	limitTags(posts)
+
+	data := struct {
+		Account    *core.Account
+		Blog       core.Blog
+		Subscribed bool
+		Unread     int
+		Results    string
+		MorePages  bool
+		NextPage   string
+		Search     string
+		Posts      []core.Post
+	}{
+		Account:    account,
+		Blog:       blog,
+		Subscribed: subscribed,
+		Unread:     unread,
+		Results:    countPosts(count),
+		MorePages:  !next.IsZero(),
+		NextPage:   blogPath(blog.ID) + "?" + url.Values{"cursor": {next.String()}}.Encode(),
+		Posts:      posts,
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// blogPath returns the path of a blog's page.
+func blogPath(id int) string {
+	return fmt.Sprintf("/blog/%d", id)
+}
@@ -0,0 +1,164 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+	"github.com/theandrew168/bloggulus/internal/web"
+)
+
+func TestHandleBlogs(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	post := test.CreateMockPost(storage, t)
+	link := fmt.Sprintf(`href="/blog/%d"`, post.Blog.ID)
+
+	// look for the blog across every page of blogs
+	found := false
+	for p := 0; !found; p++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", fmt.Sprintf("/blog?p=%d", p), nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+		}
+
+		page := string(body)
+		found = strings.Contains(page, link)
+		if !found && !strings.Contains(page, fmt.Sprintf(`href="/blog?p=%d"`, p+1)) {
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected link to blog page")
+	}
+}
+
+func TestHandleBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	post := test.CreateMockPost(storage, t)
+
+	// posts from other blogs shouldn't show up
+	other := test.CreateMockPost(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/blog/%d", post.Blog.ID), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	page := string(body)
+	if !strings.Contains(strings.ToLower(page), strings.ToLower(post.Title)) {
+		t.Fatalf("expected blog's post title on page")
+	}
+	if strings.Contains(strings.ToLower(page), strings.ToLower(other.Title)) {
+		t.Fatalf("expected other blog's post title to be missing from page")
+	}
+	if !strings.Contains(page, "1 post") {
+		t.Fatalf("expected count of blog's posts on page")
+	}
+}
+
+func TestHandleBlogPagination(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	// ensure the blog has more than one page of posts
+	blog := test.CreateMockBlog(storage, t)
+	for i := 0; i < 16; i++ {
+		post := test.NewMockPost(blog)
+		err := storage.CreatePost(context.Background(), &post)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/blog/%d", blog.ID), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	next := fmt.Sprintf("/blog/%d?cursor=", blog.ID)
+	if !strings.Contains(string(body), next) {
+		t.Fatalf("expected link to %s", next)
+	}
+}
+
+func TestHandleBlogNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	paths := []string{
+		"/blog/999999999",
+		"/blog/abc",
+	}
+
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 404 {
+			t.Fatalf("%s: want %v, got %v", path, 404, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+type contextKey string
+
+const accountContextKey = contextKey("account")
+
+func contextSetAccount(r *http.Request, account core.Account) *http.Request {
+	ctx := context.WithValue(r.Context(), accountContextKey, &account)
+	return r.WithContext(ctx)
+}
+
+// contextGetAccount returns the logged in account (or nil if the request
+// isn't logged in).
+func contextGetAccount(r *http.Request) *core.Account {
+	account, ok := r.Context().Value(accountContextKey).(*core.Account)
+	if !ok {
+		return nil
+	}
+
+	return account
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"html/template"
 	"net/http"
+
+	"github.com/theandrew168/bloggulus/internal/core"
 )
 
 func (app *Application) errorResponse(w http.ResponseWriter, r *http.Request, status int, tmpl string) {
@@ -20,9 +22,17 @@ func (app *Application) errorResponse(w http.ResponseWriter, r *http.Request, st
 		return
 	}
 
+	// the nav bar still reflects who is logged in
+	data := struct {
+		Account *core.Account
+		Search  string
+	}{
+		Account: contextGetAccount(r),
+	}
+
 	// render template to a temp buffer
 	var buf bytes.Buffer
-	err = ts.Execute(&buf, nil)
+	err = ts.Execute(&buf, data)
 	if err != nil {
 		app.logger.Println(err)
 		http.Error(w, "Internal server error", 500)
@@ -34,6 +44,10 @@ func (app *Application) errorResponse(w http.ResponseWriter, r *http.Request, st
 	w.Write(buf.Bytes())
 }
 
+func (app *Application) badRequestResponse(w http.ResponseWriter, r *http.Request) {
+	app.errorResponse(w, r, 400, "400.page.tmpl")
+}
+
 func (app *Application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	app.errorResponse(w, r, 404, "404.page.tmpl")
 }
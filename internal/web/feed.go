@@ -0,0 +1,159 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/feed"
+)
+
+var feedSize = 20
+
+type feedWriter func(w io.Writer, ch feed.Channel) error
+
+func (app *Application) HandleFeedAtom(w http.ResponseWriter, r *http.Request) {
+	app.handleFeed(w, r, "application/atom+xml; charset=utf-8", feed.WriteAtom)
+}
+
+func (app *Application) HandleFeedRSS(w http.ResponseWriter, r *http.Request) {
+	app.handleFeed(w, r, "application/rss+xml; charset=utf-8", feed.WriteRSS)
+}
+
+func (app *Application) HandleFeedJSON(w http.ResponseWriter, r *http.Request) {
+	app.handleFeed(w, r, "application/feed+json; charset=utf-8", feed.WriteJSON)
+}
+
+func (app *Application) handleFeed(w http.ResponseWriter, r *http.Request, contentType string, write feedWriter) {
+	qs := r.URL.Query()
+
+	// check search, blog and tag params (the search may hold filters too)
+	q := qs.Get("q")
+	blogID := qs.Get("blog")
+	tagNames := qs["tag"]
+
+	search := core.ParseSearch(q, time.Now())
+
+	base := baseURL(r)
+	link := base + "/"
+	var titles []string
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	if blogID != "" {
+		id, err := strconv.Atoi(blogID)
+		if err != nil || id < 0 {
+			app.notFoundResponse(w, r)
+			return
+		}
+
+		blog, err := app.storage.ReadBlog(ctx, id)
+		if err != nil {
+			if errors.Is(err, core.ErrNotExist) {
+				app.notFoundResponse(w, r)
+				return
+			}
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		titles = append(titles, blog.Title)
+		link = blog.SiteURL
+		search.BlogIDs = append(search.BlogIDs, blog.ID)
+	}
+
+	for _, name := range tagNames {
+		tag, err := app.storage.ReadTagByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, core.ErrNotExist) {
+				app.notFoundResponse(w, r)
+				return
+			}
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		titles = append(titles, tag.Name)
+		if blogID == "" && len(tagNames) == 1 {
+			link = base + tagPath(tag.Name)
+		}
+		search.Tags = append(search.Tags, tag.Name)
+	}
+
+	if q != "" {
+		titles = append(titles, q)
+		if blogID == "" {
+			link = base + "/?q=" + url.QueryEscape(q)
+		}
+	}
+
+	title := "Bloggulus"
+	if len(titles) > 0 {
+		title += " - " + strings.Join(titles, " - ")
+	}
+
+	var err error
+	var posts []core.Post
+	if search.IsZero() {
+		posts, _, err = app.storage.ReadPostsAfter(ctx, core.Cursor{}, feedSize)
+	} else {
+		// feed readers expect the newest posts first (unless asked otherwise)
+		if search.Sort == "" {
+			search.Sort = core.SortDate
+		}
+		posts, _, err = app.storage.FilterPosts(ctx, search, core.Cursor{}, feedSize)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// the feed is as fresh as its newest post
+	updated := time.Now()
+	if len(posts) > 0 {
+		updated = posts[0].Updated
+		for _, post := range posts {
+			if post.Updated.After(updated) {
+				updated = post.Updated
+			}
+		}
+	}
+
+	ch := feed.Channel{
+		Title:   title,
+		Link:    link,
+		FeedURL: base + r.URL.RequestURI(),
+		Updated: updated,
+		Posts:   posts,
+	}
+
+	// render feed to a temp buffer
+	var buf bytes.Buffer
+	err = write(&buf, ch)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// baseURL determines the scheme and host used to reach the application,
+// taking into account any TLS-terminating proxy in front of it.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
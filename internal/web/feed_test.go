@@ -0,0 +1,279 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+	"github.com/theandrew168/bloggulus/internal/web"
+)
+
+func TestHandleFeed(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/feed.atom", "application/atom+xml"},
+		{"/feed.rss", "application/rss+xml"},
+		{"/feed.json", "application/feed+json"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", tt.path, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: want %v, got %v", tt.path, 200, resp.StatusCode)
+		}
+
+		got := resp.Header.Get("Content-Type")
+		if !strings.HasPrefix(got, tt.contentType) {
+			t.Fatalf("%s: want %v, got %v", tt.path, tt.contentType, got)
+		}
+	}
+}
+
+func TestHandleFeedBlog(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	post := test.CreateMockPost(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/feed.atom?blog=%d", post.Blog.ID), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	if !strings.Contains(string(body), post.URL) {
+		t.Fatalf("expected blog's post in feed")
+	}
+}
+
+func TestHandleFeedBlogNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/feed.rss?blog=999999999", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleFeedSearch(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+
+	// create a searchable post
+	post := core.NewPost(
+		test.RandomURL(32),
+		"python rust",
+		test.RandomTime(),
+		blog,
+	)
+	err := storage.CreatePost(context.Background(), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/feed.json?q=python+rust", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	if !strings.Contains(string(body), post.Title) {
+		t.Fatalf("expected searched post in feed")
+	}
+}
+
+func TestHandleFeedSearchByDate(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+	word := test.RandomWord(16)
+
+	// an older post that ranks higher for the word
+	older := core.NewPost(
+		test.RandomURL(32),
+		strings.Repeat(word+" ", 4)+test.RandomString(16),
+		time.Now().Add(-48*time.Hour),
+		blog,
+	)
+	older.Body = strings.Repeat(word+" ", 16)
+	err := storage.CreatePost(context.Background(), &older)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// and a newer post that only mentions it once
+	newer := core.NewPost(
+		test.RandomURL(32),
+		word+" "+test.RandomString(16),
+		time.Now().Add(-24*time.Hour),
+		blog,
+	)
+	err = storage.CreatePost(context.Background(), &newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/feed.json?q="+word, nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	// the newest post comes first regardless of rank
+	first := strings.Index(string(body), newer.Title)
+	second := strings.Index(string(body), older.Title)
+	if first == -1 || second == -1 {
+		t.Fatalf("expected searched posts in feed")
+	}
+	if first > second {
+		t.Fatalf("expected newer post before older post")
+	}
+}
+
+func TestHandleFeedTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	tag := test.CreateMockTag(storage, t)
+	blog := test.CreateMockBlog(storage, t)
+
+	tagged := test.NewMockPost(blog)
+	tagged.Body = "all about " + tag.Name
+	err := storage.CreatePost(context.Background(), &tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	untagged := test.NewMockPost(blog)
+	untagged.Body = "all about " + test.RandomWord(16)
+	err = storage.CreatePost(context.Background(), &untagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tags combine with the other filters
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/feed.atom?blog=%d&tag=%s", blog.ID, tag.Name), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	if !strings.Contains(string(body), tagged.URL) {
+		t.Fatalf("expected tagged post in feed")
+	}
+	if strings.Contains(string(body), untagged.URL) {
+		t.Fatalf("unexpected untagged post in feed")
+	}
+}
+
+func TestHandleFeedTagNotExist(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/feed.rss?tag="+test.RandomWord(16), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
@@ -2,94 +2,168 @@ package web
 
 import (
 	"context"
+	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/theandrew168/bloggulus/internal/core"
 )
 
+var indexFuncs = template.FuncMap{
+	// search snippets are escaped by storage before matches are marked
+	"snippet": func(s string) template.HTML {
+		return template.HTML(s)
+	},
+	"tagPath":    tagPath,
+	"blogPath":   blogPath,
+	"postPath":   postPath,
+	"countPosts": countPosts,
+}
+
 func (app *Application) HandleIndex(w http.ResponseWriter, r *http.Request) {
 	files := []string{
 		"index.page.tmpl",
+		"posts.partial.tmpl",
 		"base.layout.tmpl",
 	}
 
-	ts, err := template.ParseFS(app.templates, files...)
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	// check page param
-	p, err := strconv.Atoi(r.URL.Query().Get("p"))
-	if err != nil {
-		p = 0
-	}
-
-	// check search param
+	// check search param (which may also hold filters like tag:Go)
 	q := r.URL.Query().Get("q")
+	search := core.ParseSearch(q, time.Now())
 
-	var count int
-	var posts []core.Post
+	// logged in readers see posts from the blogs they follow
+	account := contextGetAccount(r)
+	personal := account != nil && search.IsZero()
 
-	if q != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
-		defer cancel()
+	var posts []core.Post
+	var next url.Values
+	var results string
+	var noSubscriptions bool
+
+	// check page param (offset pagination from older links, which never
+	// had filters)
+	if r.URL.Query().Get("p") != "" && !search.HasFilters() && !personal {
+		p, err := strconv.Atoi(r.URL.Query().Get("p"))
+		if err != nil || p < 0 {
+			p = 0
+		}
 
-		// search if requested
-		count, err = app.storage.CountSearchPosts(ctx, q)
+		var more bool
+		posts, more, err = app.readPostsByPage(q, p)
 		if err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
 
-		ctx, cancel = context.WithTimeout(context.Background(), queryTimeout)
-		defer cancel()
-
-		posts, err = app.storage.SearchPosts(ctx, q, pageSize, p*pageSize)
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
+		if more {
+			next = url.Values{"p": {strconv.Itoa(p + 1)}}
 		}
 	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
-		defer cancel()
-
-		// else just read recent
-		count, err = app.storage.CountPosts(ctx)
+		// an invalid cursor just starts over from the beginning
+		cursor, err := core.ParseCursor(r.URL.Query().Get("cursor"))
 		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
+			cursor = core.Cursor{}
 		}
 
-		ctx, cancel = context.WithTimeout(context.Background(), queryTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 		defer cancel()
 
-		posts, err = app.storage.ReadPosts(ctx, pageSize, p*pageSize)
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
+		var nextCursor core.Cursor
+		if !search.IsZero() {
+			// search if requested
+			posts, nextCursor, err = app.storage.FilterPosts(ctx, search, cursor, pageSize)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			count, err := app.storage.CountFilteredPosts(ctx, search)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			results = countPosts(count)
+		} else if personal {
+			posts, nextCursor, err = app.storage.ReadSubscribedPostsAfter(ctx, account.ID, cursor, pageSize)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			// point new accounts towards some blogs to follow
+			if len(posts) == 0 && cursor.IsZero() {
+				blogs, err := app.storage.ReadSubscriptions(ctx, account.ID, 1, 0)
+				if err != nil {
+					app.serverErrorResponse(w, r, err)
+					return
+				}
+				noSubscriptions = len(blogs) == 0
+			}
+		} else {
+			// else just read recent
+			posts, nextCursor, err = app.storage.ReadPostsAfter(ctx, cursor, pageSize)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
 		}
-	}
 
-	// limit each post to 3 tags
-	for i := 0; i < len(posts); i++ {
-		if len(posts[i].Tags) > 3 {
-			posts[i].Tags = posts[i].Tags[:3]
+		if !nextCursor.IsZero() {
+			next = url.Values{"cursor": {nextCursor.String()}}
 		}
 	}
 
+	// keep searching for the same thing on the next page
+	if next != nil && q != "" {
+		next.Set("q", q)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	err = app.markPosts(ctx, r, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	limitTags(posts)
+
+	heading := "Recent Posts"
+	if q != "" {
+		heading = "Search Results"
+	} else if personal {
+		heading = "Your Feed"
+	}
+
 	data := struct {
-		MorePages bool
-		NextPage  int
-		Search    string
-		Posts     []core.Post
+		Account         *core.Account
+		Heading         string
+		Results         string
+		NoSubscriptions bool
+		MorePages       bool
+		NextPage        string
+		Search          string
+		Posts           []core.Post
 	}{
-		MorePages: (p+1)*pageSize < count,
-		NextPage:  p + 1,
-		Search:    q,
-		Posts:     posts,
+		Account:         account,
+		Heading:         heading,
+		Results:         results,
+		NoSubscriptions: noSubscriptions,
+		MorePages:       next != nil,
+		NextPage:        "/?" + next.Encode(),
+		Search:          q,
+		Posts:           posts,
 	}
 
 	err = ts.Execute(w, data)
@@ -98,3 +172,64 @@ func (app *Application) HandleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// readPostsByPage reads a page of posts by offset and reports whether
+// there are more pages after it.
+func (app *Application) readPostsByPage(q string, p int) ([]core.Post, bool, error) {
+	if q != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+
+		// search if requested
+		count, err := app.storage.CountSearchPosts(ctx, q)
+		if err != nil {
+			return nil, false, err
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+
+		posts, err := app.storage.SearchPosts(ctx, q, pageSize, p*pageSize)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return posts, (p+1)*pageSize < count, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// else just read recent
+	count, err := app.storage.CountPosts(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	posts, err := app.storage.ReadPosts(ctx, pageSize, p*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return posts, (p+1)*pageSize < count, nil
+}
+
+// countPosts describes how many posts were found.
+func countPosts(count int) string {
+	if count == 1 {
+		return "1 post"
+	}
+	return fmt.Sprintf("%d posts", count)
+}
+
+// limitTags limits each post to its 3 most relevant tags.
+func limitTags(posts []core.Post) {
+	for i := 0; i < len(posts); i++ {
+		if len(posts[i].Tags) > 3 {
+			posts[i].Tags = posts[i].Tags[:3]
+		}
+	}
+}
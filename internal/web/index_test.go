@@ -2,8 +2,10 @@ package web_test
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -43,6 +45,9 @@ func TestHandleIndex(t *testing.T) {
 	if !strings.Contains(strings.ToLower(page), strings.ToLower(post.Title)) {
 		t.Fatalf("expected recent post title on page")
 	}
+	if !strings.Contains(page, post.Summary) {
+		t.Fatalf("expected recent post summary on page")
+	}
 }
 
 func TestHandleIndexSearch(t *testing.T) {
@@ -91,3 +96,103 @@ func TestHandleIndexSearch(t *testing.T) {
 		t.Fatalf("expected searched post title on page")
 	}
 }
+
+func TestHandleIndexPagination(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	// ensure there is more than one page of posts
+	for i := 0; i < 16; i++ {
+		test.CreateMockPost(storage, t)
+	}
+
+	tests := []struct {
+		path string
+		next string
+	}{
+		{"/", "/?cursor="},
+		{"/?p=0", "/?p=1"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", tt.path, nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: want %v, got %v", tt.path, 200, resp.StatusCode)
+		}
+
+		page := string(body)
+		if !strings.Contains(page, tt.next) {
+			t.Fatalf("%s: expected link to %s", tt.path, tt.next)
+		}
+	}
+}
+
+func TestHandleIndexFilter(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	blog := test.CreateMockBlog(storage, t)
+	other := test.CreateMockBlog(storage, t)
+	word := test.RandomWord(16)
+
+	want := test.NewMockPost(blog)
+	want.Body = word
+	err := storage.CreatePost(context.Background(), &want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unwanted := test.NewMockPost(other)
+	unwanted.Body = word
+	err = storage.CreatePost(context.Background(), &unwanted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := url.QueryEscape(fmt.Sprintf("%s blog:%d", word, blog.ID))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q="+q, nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	page := strings.ToLower(string(body))
+	if !strings.Contains(page, strings.ToLower(want.Title)) {
+		t.Fatalf("expected filtered post title on page")
+	}
+	if strings.Contains(page, strings.ToLower(unwanted.Title)) {
+		t.Fatalf("expected post from another blog to be missing from page")
+	}
+	if !strings.Contains(page, "1 post") {
+		t.Fatalf("expected count of matching posts on page")
+	}
+}
@@ -0,0 +1,198 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+// HandleVisitPost sends readers on to a post (after marking it read for
+// those who are logged in). It stays a GET so that post links still work
+// as links (opened in new tabs and so on) but only visits from the site's
+// own pages count since other sites can link here too.
+func (app *Application) HandleVisitPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	post, err := app.storage.ReadPost(ctx, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	account := contextGetAccount(r)
+	if account != nil && sameOrigin(r) {
+		err = app.storage.MarkPostRead(ctx, account.ID, post.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, post.URL, http.StatusFound)
+}
+
+func (app *Application) HandleSavePost(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// saving twice (such as by resubmitting the form) is harmless
+	err = app.storage.SavePost(ctx, account.ID, id)
+	if err != nil && !errors.Is(err, core.ErrExist) {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, backPath(r), http.StatusSeeOther)
+}
+
+func (app *Application) HandleUnsavePost(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// as is unsaving twice
+	err = app.storage.UnsavePost(ctx, account.ID, id)
+	if err != nil && !errors.Is(err, core.ErrNotExist) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, backPath(r), http.StatusSeeOther)
+}
+
+func (app *Application) HandleSaved(w http.ResponseWriter, r *http.Request) {
+	files := []string{
+		"index.page.tmpl",
+		"posts.partial.tmpl",
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	account := contextGetAccount(r)
+
+	// an invalid cursor just starts over from the beginning
+	cursor, err := core.ParseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		cursor = core.Cursor{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	posts, next, err := app.storage.ReadSavedPostsAfter(ctx, account.ID, cursor, pageSize)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.markPosts(ctx, r, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	limitTags(posts)
+
+	data := struct {
+		Account         *core.Account
+		Heading         string
+		Results         string
+		NoSubscriptions bool
+		MorePages       bool
+		NextPage        string
+		Search          string
+		Posts           []core.Post
+	}{
+		Account:   account,
+		Heading:   "Saved Posts",
+		MorePages: !next.IsZero(),
+		NextPage:  "/saved?" + url.Values{"cursor": {next.String()}}.Encode(),
+		Posts:     posts,
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// markPosts shows which posts the logged in account has read or saved.
+func (app *Application) markPosts(ctx context.Context, r *http.Request, posts []core.Post) error {
+	account := contextGetAccount(r)
+	if account == nil {
+		return nil
+	}
+
+	return app.storage.ReadPostMarks(ctx, account.ID, posts)
+}
+
+// postPath returns the path of a post's actions (such as /visit).
+func postPath(id int) string {
+	return fmt.Sprintf("/post/%d", id)
+}
+
+// backPath returns the local page that a form was submitted from.
+func backPath(r *http.Request) string {
+	referer, err := url.Parse(r.Referer())
+	if err != nil || referer.Host != r.Host {
+		return "/"
+	}
+
+	return safeRedirect(referer.RequestURI())
+}
+
+// sameOrigin reports whether a request came from one of the site's own
+// pages (going by the browser's fetch metadata, else the referer).
+func sameOrigin(r *http.Request) bool {
+	site := r.Header.Get("Sec-Fetch-Site")
+	if site != "" {
+		return site == "same-origin"
+	}
+
+	referer, err := url.Parse(r.Referer())
+	return err == nil && referer.Host == r.Host
+}
@@ -0,0 +1,271 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+	"github.com/theandrew168/bloggulus/internal/web"
+)
+
+func TestHandleVisitPost(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	post := test.CreateMockPost(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/post/%d/visit", post.ID), nil)
+	r.Header.Set("Referer", "http://example.com/")
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 302 {
+		t.Fatalf("want %v, got %v", 302, resp.StatusCode)
+	}
+	if resp.Header.Get("Location") != post.URL {
+		t.Fatalf("want %v, got %v", post.URL, resp.Header.Get("Location"))
+	}
+
+	// the visit should mark the post read
+	posts := []core.Post{post}
+	err := storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !posts[0].Read {
+		t.Fatal("post should be read after visiting it")
+	}
+}
+
+func TestHandleVisitPostCrossSite(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	post := test.CreateMockPost(storage, t)
+
+	// follow a link to the post from another site
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/post/%d/visit", post.ID), nil)
+	r.Header.Set("Sec-Fetch-Site", "cross-site")
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 302 {
+		t.Fatalf("want %v, got %v", 302, resp.StatusCode)
+	}
+	if resp.Header.Get("Location") != post.URL {
+		t.Fatalf("want %v, got %v", post.URL, resp.Header.Get("Location"))
+	}
+
+	// the visit should not mark the post read
+	posts := []core.Post{post}
+	err := storage.ReadPostMarks(context.Background(), account.ID, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if posts[0].Read {
+		t.Fatal("post should not be read after visiting it from another site")
+	}
+}
+
+func TestHandleVisitPostLoggedOut(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	post := test.CreateMockPost(storage, t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/post/%d/visit", post.ID), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 302 {
+		t.Fatalf("want %v, got %v", 302, resp.StatusCode)
+	}
+	if resp.Header.Get("Location") != post.URL {
+		t.Fatalf("want %v, got %v", post.URL, resp.Header.Get("Location"))
+	}
+}
+
+func TestHandleVisitPostNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/post/999999999/visit", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
+
+func TestHandleSaved(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+	cookie := &http.Cookie{Name: "session", Value: token}
+
+	post := test.CreateMockPost(storage, t)
+	other := test.CreateMockPost(storage, t)
+
+	// save a post from its blog's page
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", fmt.Sprintf("/post/%d/save", post.ID), nil)
+	r.Header.Set("Referer", fmt.Sprintf("http://example.com/blog/%d", post.Blog.ID))
+	r.AddCookie(cookie)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+
+	// and end up back on that page
+	back := fmt.Sprintf("/blog/%d", post.Blog.ID)
+	if resp.Header.Get("Location") != back {
+		t.Fatalf("want %v, got %v", back, resp.Header.Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/saved", nil)
+	r.AddCookie(cookie)
+
+	router.ServeHTTP(w, r)
+
+	resp = w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	page := string(body)
+	if !strings.Contains(page, post.Summary) {
+		t.Fatalf("expected saved post on page")
+	}
+	if strings.Contains(page, other.Summary) {
+		t.Fatalf("unexpected unsaved post on page")
+	}
+	if !strings.Contains(page, fmt.Sprintf("/post/%d/unsave", post.ID)) {
+		t.Fatalf("expected unsave button on page")
+	}
+}
+
+func TestHandleSavedLoggedOut(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/saved", nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 303 {
+		t.Fatalf("want %v, got %v", 303, resp.StatusCode)
+	}
+	if resp.Header.Get("Location") != "/login?next=%2Fsaved" {
+		t.Fatalf("want %v, got %v", "/login?next=%2Fsaved", resp.Header.Get("Location"))
+	}
+}
+
+func TestHandleBlogsUnread(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	account, _ := test.CreateMockAccount(storage, t)
+	token := test.CreateMockSession(storage, t, account)
+
+	post := test.CreateMockPost(storage, t)
+	err := storage.CreateSubscription(context.Background(), account.ID, post.Blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", fmt.Sprintf("/blog/%d", post.Blog.ID), nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	page := string(body)
+	if !strings.Contains(page, "1 unread") {
+		t.Fatalf("expected unread count on page")
+	}
+	if !strings.Contains(page, fmt.Sprintf("/post/%d/visit", post.ID)) {
+		t.Fatalf("expected post to link through the visit endpoint")
+	}
+}
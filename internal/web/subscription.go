@@ -0,0 +1,60 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (app *Application) HandleSubscribe(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// subscribing twice (such as by resubmitting the form) is harmless
+	err = app.storage.CreateSubscription(ctx, account.ID, id)
+	if err != nil && !errors.Is(err, core.ErrExist) {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, blogPath(id), http.StatusSeeOther)
+}
+
+func (app *Application) HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	account := contextGetAccount(r)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// as is unsubscribing twice
+	err = app.storage.DeleteSubscription(ctx, account.ID, id)
+	if err != nil && !errors.Is(err, core.ErrNotExist) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, blogPath(id), http.StatusSeeOther)
+}
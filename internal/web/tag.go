@@ -0,0 +1,160 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/theandrew168/bloggulus/internal/core"
+)
+
+func (app *Application) HandleTags(w http.ResponseWriter, r *http.Request) {
+	files := []string{
+		"tags.page.tmpl",
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// check page param
+	p, err := strconv.Atoi(r.URL.Query().Get("p"))
+	if err != nil || p < 0 {
+		p = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// read one extra tag to determine if another page exists
+	tags, err := app.storage.ReadTags(ctx, tagPageSize+1, p*tagPageSize)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	more := len(tags) > tagPageSize
+	if more {
+		tags = tags[:tagPageSize]
+	}
+
+	data := struct {
+		Account   *core.Account
+		MorePages bool
+		NextPage  string
+		Search    string
+		Tags      []core.Tag
+	}{
+		Account:   contextGetAccount(r),
+		MorePages: more,
+		NextPage:  "/tag?p=" + strconv.Itoa(p+1),
+		Tags:      tags,
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *Application) HandleTag(w http.ResponseWriter, r *http.Request) {
+	files := []string{
+		"index.page.tmpl",
+		"posts.partial.tmpl",
+		"base.layout.tmpl",
+	}
+
+	ts, err := template.New(files[0]).Funcs(indexFuncs).ParseFS(app.templates, files...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// chi only leaves the param escaped when the path needed escaping
+	name := chi.URLParam(r, "name")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tag, err := app.storage.ReadTagByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, core.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// aliases (and other spellings) lead to the tag's own page
+	if name != tag.Name {
+		target := tagPath(tag.Name)
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	// an invalid cursor just starts over from the beginning
+	cursor, err := core.ParseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		cursor = core.Cursor{}
+	}
+
+	posts, next, err := app.storage.ReadPostsByTagAfter(ctx, tag.ID, cursor, pageSize)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.markPosts(ctx, r, posts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	limitTags(posts)
+
+	data := struct {
+		Account         *core.Account
+		Heading         string
+		Results         string
+		NoSubscriptions bool
+		MorePages       bool
+		NextPage        string
+		Search          string
+		Posts           []core.Post
+	}{
+		Account:   contextGetAccount(r),
+		Heading:   "Posts tagged " + tag.Name,
+		Results:   countPosts(tag.PostCount),
+		MorePages: !next.IsZero(),
+		NextPage:  tagPath(tag.Name) + "?" + url.Values{"cursor": {next.String()}}.Encode(),
+		Posts:     posts,
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// tagPath returns the path of a tag's page. Names are escaped so that
+// ones containing a slash stay within a single path segment.
+func tagPath(name string) string {
+	return "/tag/" + url.PathEscape(name)
+}
@@ -0,0 +1,160 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/theandrew168/bloggulus/internal/postgresql"
+	"github.com/theandrew168/bloggulus/internal/test"
+	"github.com/theandrew168/bloggulus/internal/web"
+)
+
+func TestHandleTags(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	tag := test.CreateMockTag(storage, t)
+	link := fmt.Sprintf(`href="/tag/%s"`, tag.Name)
+
+	// look for the tag across every page of tags
+	found := false
+	for p := 0; !found; p++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", fmt.Sprintf("/tag?p=%d", p), nil)
+
+		router := app.Router()
+		router.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 200 {
+			t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+		}
+
+		page := string(body)
+		found = strings.Contains(page, link)
+		if !found && !strings.Contains(page, fmt.Sprintf(`href="/tag?p=%d"`, p+1)) {
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected link to tag page")
+	}
+}
+
+func TestHandleTag(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	tag := test.CreateMockTag(storage, t)
+	blog := test.CreateMockBlog(storage, t)
+
+	tagged := test.NewMockPost(blog)
+	tagged.Body = "all about " + tag.Name
+	err := storage.CreatePost(context.Background(), &tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	untagged := test.NewMockPost(blog)
+	untagged.Body = "all about " + test.RandomWord(16)
+	err = storage.CreatePost(context.Background(), &untagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/tag/"+tag.Name, nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("want %v, got %v", 200, resp.StatusCode)
+	}
+
+	page := strings.ToLower(string(body))
+	if !strings.Contains(page, strings.ToLower(tagged.Title)) {
+		t.Fatalf("expected tagged post title on page")
+	}
+	if strings.Contains(page, strings.ToLower(untagged.Title)) {
+		t.Fatalf("expected untagged post title to be missing from page")
+	}
+}
+
+func TestHandleTagAlias(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	tag := test.NewMockTag()
+	tag.Aliases = []string{"Ruby " + test.RandomWord(16)}
+	err := storage.CreateTag(context.Background(), &tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// aliases redirect to the tag's own page
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/tag/"+url.PathEscape(tag.Aliases[0]), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 302 {
+		t.Fatalf("want %v, got %v", 302, resp.StatusCode)
+	}
+
+	location := "/tag/" + tag.Name
+	if resp.Header.Get("Location") != location {
+		t.Fatalf("want %v, got %v", location, resp.Header.Get("Location"))
+	}
+}
+
+func TestHandleTagNotFound(t *testing.T) {
+	conn := test.ConnectDB(t)
+	defer conn.Close()
+
+	storage := postgresql.NewStorage(conn)
+	logger := test.NewLogger()
+	app := web.NewApplication(storage, logger)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/tag/"+test.RandomWord(16), nil)
+
+	router := app.Router()
+	router.ServeHTTP(w, r)
+
+	resp := w.Result()
+	if resp.StatusCode != 404 {
+		t.Fatalf("want %v, got %v", 404, resp.StatusCode)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/theandrew168/bloggulus/internal/config"
 	"github.com/theandrew168/bloggulus/internal/core"
 	"github.com/theandrew168/bloggulus/internal/feed"
+	"github.com/theandrew168/bloggulus/internal/mail"
+	"github.com/theandrew168/bloggulus/internal/opml"
 	"github.com/theandrew168/bloggulus/internal/postgresql"
 	"github.com/theandrew168/bloggulus/internal/task"
 	"github.com/theandrew168/bloggulus/internal/web"
@@ -49,7 +51,9 @@ func main() {
 
 	// check for action flags
 	migrate := flag.Bool("migrate", false, "apply migrations and exit")
-	addblog := flag.String("addblog", "", "rss / atom feed to add")
+	addblog := flag.String("addblog", "", "rss / atom feed (or blog homepage) to add")
+	importOPML := flag.String("import-opml", "", "opml file of feeds to add")
+	retag := flag.Bool("retag", false, "recompute the tags of every post and exit")
 	flag.Parse()
 
 	// load user-defined config (if specified), else use defaults
@@ -84,19 +88,35 @@ func main() {
 	// init storage interface
 	storage := postgresql.NewStorage(conn)
 
-	// init default feed reader
-	reader := feed.NewReader()
+	// init feed reader (polite to hosts shared by many blogs)
+	reader := feed.NewReader(cfg.SyncHostConcurrency, cfg.SyncHostDelay.Duration)
 
 	// add a blog and exit now if requested
 	if *addblog != "" {
 		feedURL := *addblog
 		logger.Printf("adding blog: %s\n", feedURL)
 
-		blog, err := reader.ReadBlog(feedURL)
+		// the URL may be a homepage that links to one or more feeds
+		candidates, err := reader.DiscoverFeeds(feedURL)
 		if err != nil {
 			logger.Fatalln(err)
 		}
-		logger.Printf("  found: %s\n", blog.Title)
+		for _, candidate := range candidates {
+			logger.Printf("  candidate: %s (%s)\n", candidate.URL, candidate.Type)
+		}
+
+		// read the first candidate that works (rather than discovering again)
+		var blog core.Blog
+		for _, candidate := range candidates {
+			blog, err = reader.ReadBlog(candidate.URL)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			logger.Fatalln(err)
+		}
+		logger.Printf("  found: %s (%s)\n", blog.Title, blog.FeedURL)
 
 		err = storage.CreateBlog(context.Background(), &blog)
 		if err != nil {
@@ -110,18 +130,67 @@ func main() {
 		return
 	}
 
+	// import blogs from an OPML file and exit now if requested
+	if *importOPML != "" {
+		logger.Printf("importing blogs: %s\n", *importOPML)
+
+		f, err := os.Open(*importOPML)
+		if err != nil {
+			logger.Fatalln(err)
+		}
+		defer f.Close()
+
+		doc, err := opml.Parse(f)
+		if err != nil {
+			logger.Fatalln(err)
+		}
+
+		results := opml.Import(context.Background(), storage, reader, doc.Feeds())
+		printImportResults(logger, results)
+
+		return
+	}
+
+	// recompute post tags and exit now if requested
+	if *retag {
+		logger.Println("retagging posts")
+
+		err = storage.RetagPosts(context.Background())
+		if err != nil {
+			logger.Fatalln(err)
+		}
+
+		return
+	}
+
 	// init task worker
 	worker := task.NewWorker(logger)
 
-	// kick off blog sync task
-	syncBlogs := worker.SyncBlogs(storage, reader)
-	go syncBlogs.Run(1 * time.Hour)
+	// kick off blog sync task (each blog is synced on its own schedule)
+	syncBlogs := worker.SyncBlogs(storage, reader, cfg.SyncConcurrency)
+	go syncBlogs.Run(1 * time.Minute)
+
+	// kick off webhook delivery task (new posts are queued while syncing)
+	deliverWebhooks := worker.DeliverWebhooks(storage)
+	go deliverWebhooks.Run(15 * time.Second)
+
+	// kick off digest task (if anyone wants them)
+	if len(cfg.DigestTo) > 0 {
+		sender := mail.NewSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+
+		// the query can hold filters like tag:Go as well
+		search := core.ParseSearch(cfg.DigestQuery, time.Now())
+		search.Tags = append(search.Tags, cfg.DigestTags...)
+
+		sendDigest := worker.SendDigest(storage, sender, cfg.DigestTo, search, cfg.DigestInterval.Duration)
+		go sendDigest.Run(1 * time.Hour)
+	}
 
 	// init web application
 	webApp := web.NewApplication(storage, logger)
 
 	// init api application struct
-	apiApp := api.NewApplication(storage, logger)
+	apiApp := api.NewApplication(storage, reader, cfg.AdminToken, logger)
 
 	// setup http.Handler for static files
 	static, _ := fs.Sub(staticFS, "static")
@@ -275,3 +344,36 @@ func compareAndApplyMigrations(conn *pgxpool.Pool, migrationsFS fs.FS, logger *l
 	logger.Printf("migrations up to date\n")
 	return nil
 }
+
+func printImportResults(logger *log.Logger, results []opml.ImportResult) {
+	// group results by category (in order of first appearance)
+	var categories []string
+	groups := make(map[string][]opml.ImportResult)
+	for _, result := range results {
+		category := result.Feed.Category
+		if category == "" {
+			category = "Uncategorized"
+		}
+
+		if _, ok := groups[category]; !ok {
+			categories = append(categories, category)
+		}
+		groups[category] = append(groups[category], result)
+	}
+
+	counts := make(map[opml.ImportStatus]int)
+	for _, category := range categories {
+		logger.Printf("%s:\n", category)
+		for _, result := range groups[category] {
+			counts[result.Status]++
+			if result.Err != nil {
+				logger.Printf("  %s: %s (%v)\n", result.Status, result.Feed.URL, result.Err)
+			} else {
+				logger.Printf("  %s: %s\n", result.Status, result.Feed.URL)
+			}
+		}
+	}
+
+	logger.Printf("added %d, already existed %d, failed %d\n",
+		counts[opml.ImportAdded], counts[opml.ImportExists], counts[opml.ImportFailed])
+}
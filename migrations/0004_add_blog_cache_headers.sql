@@ -0,0 +1,3 @@
+ALTER TABLE blog
+    ADD COLUMN etag TEXT NOT NULL DEFAULT '',
+    ADD COLUMN last_modified TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE blog
+    ADD COLUMN ttl INTERVAL NOT NULL DEFAULT '0',
+    ADD COLUMN next_sync_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX blog_next_sync_at_idx ON blog(next_sync_at);
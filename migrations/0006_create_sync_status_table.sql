@@ -0,0 +1,11 @@
+CREATE TABLE sync_status (
+    blog_id INTEGER PRIMARY KEY REFERENCES blog(id) ON DELETE CASCADE,
+    last_sync_at TIMESTAMPTZ NOT NULL,
+    last_success_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    new_posts INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX sync_status_failure_count_idx ON sync_status(failure_count) WHERE failure_count > 0;
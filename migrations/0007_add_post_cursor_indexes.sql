@@ -0,0 +1,2 @@
+CREATE INDEX post_updated_id_idx ON post(updated, id);
+CREATE INDEX post_blog_id_updated_id_idx ON post(blog_id, updated, id);
@@ -0,0 +1,2 @@
+ALTER TABLE sync_status
+    ADD COLUMN updated_posts INTEGER NOT NULL DEFAULT 0;
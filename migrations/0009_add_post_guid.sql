@@ -0,0 +1,9 @@
+ALTER TABLE post
+    ADD COLUMN guid TEXT;
+
+-- existing posts have only ever been identified by their URL
+UPDATE post SET guid = url;
+
+ALTER TABLE post
+    ALTER COLUMN guid SET NOT NULL,
+    ADD CONSTRAINT post_blog_id_guid_key UNIQUE (blog_id, guid);
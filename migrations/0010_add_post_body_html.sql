@@ -0,0 +1,3 @@
+-- sanitized HTML of the post's main content (body holds the plain text)
+ALTER TABLE post
+    ADD COLUMN body_html TEXT NOT NULL DEFAULT '';
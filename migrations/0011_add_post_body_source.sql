@@ -0,0 +1,3 @@
+-- either 'feed' or 'page' (existing bodies were all read from the page)
+ALTER TABLE post
+    ADD COLUMN body_source TEXT NOT NULL DEFAULT 'page';
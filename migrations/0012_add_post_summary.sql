@@ -0,0 +1,5 @@
+ALTER TABLE post
+    ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+
+-- existing posts get the start of their body until they are next updated
+UPDATE post SET summary = left(regexp_replace(trim(body), '\s+', ' ', 'g'), 300);
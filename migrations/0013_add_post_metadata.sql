@@ -0,0 +1,7 @@
+ALTER TABLE post
+    ADD COLUMN published TIMESTAMPTZ,
+    ADD COLUMN author TEXT NOT NULL DEFAULT '',
+    ADD COLUMN first_seen TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- the best guess for existing posts is the date they've been sorted by
+UPDATE post SET first_seen = updated;
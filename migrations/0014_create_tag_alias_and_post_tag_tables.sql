@@ -0,0 +1,44 @@
+-- other names that a tag is known by (such as "Postgres" for "PostgreSQL")
+CREATE TABLE tag_alias (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    tag_id INTEGER NOT NULL REFERENCES tag(id) ON DELETE CASCADE
+);
+
+CREATE INDEX tag_alias_tag_id_idx ON tag_alias(tag_id);
+
+-- every name (including aliases) that a tag is matched against
+CREATE VIEW tag_term AS
+    SELECT id AS tag_id, name FROM tag
+    UNION ALL
+    SELECT tag_id, name FROM tag_alias;
+
+-- tags assigned to each post (computed as posts and tags are written)
+CREATE TABLE post_tag (
+    post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tag(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX post_tag_tag_id_idx ON post_tag(tag_id);
+
+INSERT INTO tag_alias
+    (name, tag_id)
+SELECT alias.name, tag.id
+FROM (VALUES
+    ('Postgres', 'PostgreSQL'),
+    ('Node.js', 'NodeJS'),
+    ('Common Lisp', 'CommonLisp'),
+    ('Emacs Lisp', 'Elisp'),
+    ('Ruby on Rails', 'Rails')
+) AS alias (name, tag_name)
+INNER JOIN tag
+    ON tag.name = alias.tag_name;
+
+-- assign tags to the posts that already exist
+INSERT INTO post_tag
+    (post_id, tag_id)
+SELECT DISTINCT post.id, tag_term.tag_id
+FROM post
+INNER JOIN tag_term
+    ON phraseto_tsquery('english', tag_term.name) @@ post.content_index;
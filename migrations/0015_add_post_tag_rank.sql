@@ -0,0 +1,20 @@
+-- how strongly a post matches each of its tags (listed most relevant first)
+ALTER TABLE post_tag
+    ADD COLUMN rank REAL NOT NULL DEFAULT 0;
+
+UPDATE post_tag
+SET rank = ranks.rank
+FROM (
+    SELECT
+        post.id AS post_id,
+        tag_term.tag_id,
+        max(ts_rank_cd(post.content_index, phraseto_tsquery('english', tag_term.name))) AS rank
+    FROM post_tag
+    INNER JOIN post
+        ON post.id = post_tag.post_id
+    INNER JOIN tag_term
+        ON tag_term.tag_id = post_tag.tag_id
+    GROUP BY post.id, tag_term.tag_id
+) AS ranks
+WHERE ranks.post_id = post_tag.post_id
+    AND ranks.tag_id = post_tag.tag_id;
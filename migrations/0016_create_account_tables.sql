@@ -0,0 +1,29 @@
+CREATE TABLE account (
+    id SERIAL PRIMARY KEY,
+    username TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- usernames are unique ignoring case
+CREATE UNIQUE INDEX account_username_idx ON account(lower(username));
+
+-- logins (identified by a hash of the token given to the client)
+CREATE TABLE session (
+    id SERIAL PRIMARY KEY,
+    hash TEXT NOT NULL UNIQUE,
+    account_id INTEGER NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    expiry TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX session_account_id_idx ON session(account_id);
+
+-- blogs that each account follows
+CREATE TABLE subscription (
+    account_id INTEGER NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    blog_id INTEGER NOT NULL REFERENCES blog(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, blog_id)
+);
+
+CREATE INDEX subscription_blog_id_idx ON subscription(blog_id);
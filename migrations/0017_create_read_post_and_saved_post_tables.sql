@@ -0,0 +1,19 @@
+-- posts that each account has clicked through to
+CREATE TABLE read_post (
+    account_id INTEGER NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, post_id)
+);
+
+CREATE INDEX read_post_post_id_idx ON read_post(post_id);
+
+-- posts that each account has saved to read later
+CREATE TABLE saved_post (
+    account_id INTEGER NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    saved_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, post_id)
+);
+
+CREATE INDEX saved_post_post_id_idx ON saved_post(post_id);
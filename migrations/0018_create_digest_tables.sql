@@ -0,0 +1,16 @@
+-- emails summarizing the posts found since the previous one
+CREATE TABLE digest (
+    id SERIAL PRIMARY KEY,
+    sent_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX digest_sent_at_idx ON digest(sent_at);
+
+-- posts included in each digest (so that they aren't sent again)
+CREATE TABLE digest_post (
+    digest_id INTEGER NOT NULL REFERENCES digest(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    PRIMARY KEY (digest_id, post_id)
+);
+
+CREATE INDEX digest_post_post_id_idx ON digest_post(post_id);
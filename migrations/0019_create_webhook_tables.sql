@@ -0,0 +1,28 @@
+-- endpoints that are sent new posts (optionally only the matching ones)
+CREATE TABLE webhook (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    query TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- queue (while pending) and log of each post sent to each webhook
+CREATE TABLE webhook_delivery (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhook(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_attempt_at TIMESTAMPTZ,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (webhook_id, post_id)
+);
+
+CREATE INDEX webhook_delivery_post_id_idx ON webhook_delivery(post_id);
+CREATE INDEX webhook_delivery_next_attempt_at_idx ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,48 @@
+-- URLs are stored as given (so that they can still be fetched) while their
+-- canonical forms (see the canonical package) are used to spot duplicates
+ALTER TABLE blog
+    ADD COLUMN feed_key TEXT;
+ALTER TABLE post
+    ADD COLUMN url_key TEXT;
+
+-- approximates canonical.URL for existing rows: the scheme and host are
+-- lowercased, http is upgraded, default ports, trailing slashes and
+-- fragments are removed (URLs with other ports are left alone)
+CREATE FUNCTION canonical_url_key(url TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN parts IS NULL OR regexp_replace(parts[1], ':(80|443)$', '') LIKE '%:%' THEN url
+        ELSE 'https://' ||
+            lower(regexp_replace(parts[1], ':(80|443)$', '')) ||
+            COALESCE(NULLIF(rtrim(parts[2], '/'), ''), '/') ||
+            COALESCE(parts[3], '')
+    END
+    FROM regexp_match(url, '^https?://([^/?#]+)([^?#]*)(\?[^#]*)?', 'i') AS parts
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE blog SET feed_key = canonical_url_key(feed_url);
+UPDATE post SET url_key = canonical_url_key(url);
+
+DROP FUNCTION canonical_url_key(TEXT);
+
+-- existing duplicates can't be merged automatically so just one of each
+-- (already canonical, else the oldest) keeps the key and the rest fall
+-- back to their own URL
+UPDATE blog SET feed_key = feed_url
+WHERE id NOT IN (
+    SELECT DISTINCT ON (feed_key) id
+    FROM blog
+    ORDER BY feed_key, feed_url = feed_key DESC, id
+);
+UPDATE post SET url_key = url
+WHERE id NOT IN (
+    SELECT DISTINCT ON (url_key) id
+    FROM post
+    ORDER BY url_key, url = url_key DESC, id
+);
+
+ALTER TABLE blog
+    ALTER COLUMN feed_key SET NOT NULL,
+    ADD CONSTRAINT blog_feed_key_key UNIQUE (feed_key);
+ALTER TABLE post
+    ALTER COLUMN url_key SET NOT NULL,
+    ADD CONSTRAINT post_url_key_key UNIQUE (url_key);
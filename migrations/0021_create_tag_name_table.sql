@@ -0,0 +1,32 @@
+-- tags and aliases share one set of names (ignoring case) so every name
+-- in use is claimed here by a trigger on the table that holds it
+CREATE TABLE tag_name (
+    name TEXT PRIMARY KEY
+);
+
+INSERT INTO tag_name
+    (name)
+SELECT DISTINCT lower(name)
+FROM tag_term;
+
+CREATE FUNCTION claim_tag_name() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM tag_name WHERE name = lower(OLD.name);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        -- fails with a unique violation if the name is already taken
+        INSERT INTO tag_name (name) VALUES (lower(NEW.name));
+        RETURN NEW;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tag_claim_name
+    AFTER INSERT OR UPDATE OF name OR DELETE ON tag
+    FOR EACH ROW EXECUTE FUNCTION claim_tag_name();
+
+CREATE TRIGGER tag_alias_claim_name
+    AFTER INSERT OR UPDATE OF name OR DELETE ON tag_alias
+    FOR EACH ROW EXECUTE FUNCTION claim_tag_name();
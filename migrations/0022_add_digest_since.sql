@@ -0,0 +1,10 @@
+-- where each digest's window of posts started (a full digest may leave
+-- posts behind that the next one has to pick up)
+ALTER TABLE digest
+    ADD COLUMN since TIMESTAMPTZ;
+
+-- earlier digests were never full enough to matter
+UPDATE digest SET since = sent_at;
+
+ALTER TABLE digest
+    ALTER COLUMN since SET NOT NULL;